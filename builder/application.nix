@@ -21,6 +21,7 @@ in {
     version,
     src,
     modules ? src + "/govendor.toml", # Path to govendor.toml manifest
+    toolModules ? null, # Path to the manifest of an alternate modfile, e.g. ./tools.govendor.toml
     go,
     subPackages ? ["."],
     ldflags ? [],
@@ -55,8 +56,30 @@ in {
               }
         '';
 
+    # Tool-only modules are left out of the application's vendor tree, and
+    # test-only modules too when tests are not run. Tools are built from
+    # their own vendor tree, which only leaves out test-only modules.
     vendorEnv = mkVendorEnv {
-      inherit go manifest src localReplaces netrcFile GOPRIVATE GONOSUMDB GONOPROXY;
+      inherit go manifest src localReplaces netrcFile GOPRIVATE GONOSUMDB GONOPROXY CGO_ENABLED;
+      excludeScopes = ["tool"] ++ lib.optional (!(attrs.doCheck or true)) "test";
+    };
+
+    # Tools declared in an alternate modfile, such as tools.mod, are built
+    # from its paired manifest and with -modfile, instead of from go.mod.
+    toolManifest =
+      if toolModules == null
+      then manifest
+      else fromTOML (readFile toolModules);
+
+    toolModFile =
+      if toolModules == null
+      then "go.mod"
+      else lib.removeSuffix ".govendor.toml" (baseNameOf toolModules) + ".mod";
+
+    toolVendorEnv = mkVendorEnv {
+      inherit go src localReplaces netrcFile GOPRIVATE GONOSUMDB GONOPROXY;
+      manifest = toolManifest;
+      excludeScopes = ["test"];
     };
 
     configurePhase =
@@ -86,12 +109,13 @@ in {
     hostTools = map (pkg:
       mkHostTool {
         inherit src go pkg;
-        inherit (vendorEnv) useSymlinks;
-        vendorEnv = vendorEnv;
-        version = manifest.tool.${pkg}.version;
+        inherit (toolVendorEnv) useSymlinks;
+        vendorEnv = toolVendorEnv;
+        version = toolManifest.tool.${pkg}.version;
+        modFile = toolModFile;
         GOWORK = "off";
       })
-    (builtins.attrNames (manifest.tool or {}));
+    (builtins.attrNames (toolManifest.tool or {}));
 
     passthru = {inherit go vendorEnv hostTools;};
   in
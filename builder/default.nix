@@ -1,26 +1,30 @@
 # Builder entry point — wires sub-modules together and exposes the public API.
 #
 # Sub-modules (each a focused file):
-#   fetch-module.nix  — fetchGoModule (downloads a single Go module)
-#   vendor-env.nix    — mkVendorEnv (constructs the vendor/ directory from a manifest)
-#   host-tool.nix     — mkHostTool, parseGoWorkModules
-#   common.nix        — commonRemovedAttrs, mkCommonAttrs (shared builder infrastructure)
-#   test-packages.nix — mkTestPackages (computes the go test package list, honouring excludedPackages)
-#   application.nix   — buildGoApplication, buildGoVendoredApplication
-#   workspace.nix     — buildGoWorkspace, buildGoVendoredWorkspace
+#   fetch-module.nix     — fetchGoModule (downloads a single Go module)
+#   fetch-module-zip.nix — fetchGoModuleZip (fetches a single Go module zip without Go)
+#   vendor-env.nix       — mkVendorEnv (constructs the vendor/ directory from a manifest)
+#   host-tool.nix        — mkHostTool, parseGoWorkModules
+#   common.nix           — commonRemovedAttrs, mkCommonAttrs (shared builder infrastructure)
+#   test-packages.nix    — mkTestPackages (computes the go test package list, honouring excludedPackages)
+#   application.nix      — buildGoApplication, buildGoVendoredApplication
+#   workspace.nix        — buildGoWorkspace, buildGoVendoredWorkspace
 {
   lib,
   stdenv,
   stdenvNoCC,
   runCommand,
   cacert,
+  fetchurl,
   git,
   jq,
+  unzip,
 }: let
   fetchGoModule = import ./fetch-module.nix {inherit lib stdenvNoCC cacert git jq;};
+  fetchGoModuleZip = import ./fetch-module-zip.nix {inherit lib stdenvNoCC fetchurl unzip;};
 
-  vendorEnvModule = import ./vendor-env.nix {inherit lib runCommand fetchGoModule;};
-  inherit (vendorEnvModule) mkVendorEnv mkModuleCopyCommands;
+  vendorEnvModule = import ./vendor-env.nix {inherit lib runCommand fetchGoModule fetchGoModuleZip;};
+  inherit (vendorEnvModule) isExcluded copiedModules warnCgoDisabled checkPrivateModules mkVendorEnv mkModuleCopyCommands;
 
   hostToolModule = import ./host-tool.nix {inherit lib stdenv runCommand;};
   inherit (hostToolModule) mkHostTool parseGoWorkModules;
@@ -40,6 +44,11 @@
       stdenv
       runCommand
       fetchGoModule
+      fetchGoModuleZip
+      isExcluded
+      copiedModules
+      warnCgoDisabled
+      checkPrivateModules
       mkModuleCopyCommands
       mkHostTool
       parseGoWorkModules
@@ -49,7 +58,7 @@
       ;
   };
 in {
-  inherit fetchGoModule mkVendorEnv;
+  inherit fetchGoModule fetchGoModuleZip mkVendorEnv;
   inherit (applicationModule) buildGoApplication buildGoVendoredApplication;
   inherit (workspaceModule) buildGoWorkspace buildGoVendoredWorkspace;
 }
@@ -0,0 +1,39 @@
+# Fetches a single Go module from its GOPROXY zip using `fetchurl`, without a Go
+# toolchain. Used for modules whose govendor.toml entry records `url` and `zip_hash`
+# (generated with `govendor --zip-urls`).
+#
+# The unpacked module is a fixed-output derivation with the same name and NAR hash
+# as fetchGoModule, so both produce the same store path, shared across Go versions.
+{
+  lib,
+  stdenvNoCC,
+  fetchurl,
+  unzip,
+}: {
+  goPackagePath,
+  version,
+  hash, # NAR hash from govendor.toml
+  url, # Proxy zip URL from govendor.toml
+  zipHash, # SHA-256 of the zip from govendor.toml
+}:
+stdenvNoCC.mkDerivation {
+  name = "${lib.replaceStrings ["/"] ["-"] goPackagePath}_${version}";
+  src = fetchurl {
+    inherit url;
+    hash = zipHash;
+  };
+  nativeBuildInputs = [unzip];
+  outputHashMode = "recursive";
+  outputHashAlgo = null;
+  outputHash = hash;
+
+  # Every file in a module zip sits under "<module>@<version>/". Extraction
+  # matches the go command: files are never executable and directory entries
+  # are ignored, so no empty directories are created.
+  buildCommand = ''
+    unzip -q "$src" -d unpacked
+    cp -r ${lib.escapeShellArg "unpacked/${goPackagePath}@${version}"} "$out"
+    find "$out" -type f -exec chmod a-x {} +
+    find "$out" -mindepth 1 -type d -empty -delete
+  '';
+}
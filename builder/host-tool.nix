@@ -54,6 +54,7 @@ in {
     GOWORK ? null, # "off" for buildGoApplication; null for workspace
     goWorkContent ? null, # generated go.work content for manifest-only workspaces
     members ? [], # workspace member paths (relative to src); [] for a single module
+    modFile ? "go.mod", # an alternate modfile, e.g. "tools.mod", is passed to go with -modfile
   }: let
     # go install builds entirely from go.mod/go.sum + vendor/ in -mod=vendor
     # mode — it never touches the application's .go sources. Restricting
@@ -69,7 +70,8 @@ in {
     # toolSrc's own inputs are therefore these toFile paths, never src
     # itself, so its hash never changes just because some unrelated part of
     # src did, for any form src takes (go-overlay#531).
-    goModPaths = ["go.mod" "go.sum"] ++ lib.concatMap (m: ["${m}/go.mod" "${m}/go.sum"]) members;
+    sumFile = lib.removeSuffix ".mod" modFile + ".sum";
+    goModPaths = [modFile sumFile] ++ lib.concatMap (m: ["${m}/go.mod" "${m}/go.sum"]) members;
 
     toolFiles = builtins.filter (f: f != null) (
       map (
@@ -104,7 +106,7 @@ in {
 
       env =
         {
-          GOFLAGS = "-mod=vendor";
+          GOFLAGS = "-mod=vendor" + optionalString (modFile != "go.mod") " -modfile=${modFile}";
           GO111MODULE = "on";
           GOTOOLCHAIN = "local";
           GOPROXY = "off";
@@ -1,16 +1,26 @@
 # Constructs a vendor directory with modules.txt from a govendor.toml manifest.
 # For Go >= 1.25, modules are symlinked for efficiency (GODEBUG=embedfollowsymlinks=1
-# handles //go:embed compatibility). For older Go, modules are copied using
-# cp -r --reflink=auto to avoid //go:embed rejecting symlinks as irregular files.
+# handles //go:embed compatibility). For older Go, modules that embed files are
+# copied using cp -r --reflink=auto to avoid //go:embed rejecting symlinks as
+# irregular files, and the rest are still symlinked.
 {
   lib,
   runCommand,
   fetchGoModule,
+  fetchGoModuleZip,
 }: let
-  inherit (lib) concatMapStringsSep escapeShellArg optionalString;
+  inherit (lib) concatMapStringsSep concatStringsSep escapeShellArg optionalString;
 
-  # Generate modules.txt entry for a single module
-  mkModuleEntry = goPackagePath: meta: let
+  # Whether every scope a module is needed in (recorded by govendor when the
+  # build itself does not need it) is excluded. Modules without a scope are
+  # needed by the build and are never excluded.
+  isExcluded = excludeScopes: meta:
+    meta ? scope && lib.all (s: builtins.elem s excludeScopes) meta.scope;
+
+  # Generate modules.txt entry for a single module. Excluded modules keep
+  # their header so go.mod and modules.txt stay consistent, but list no
+  # packages as their source is not vendored.
+  mkModuleEntry = excludeScopes: goPackagePath: meta: let
     isRemoteReplace = (meta ? replaced) && meta.replaced != goPackagePath;
     header =
       if meta ? local
@@ -22,16 +32,65 @@
       if meta.go or "" != ""
       then "## explicit; go ${meta.go}"
       else "## explicit";
-    packages = concatMapStringsSep "\n" (p: p) (meta.packages or []);
+    packages =
+      optionalString (!isExcluded excludeScopes meta)
+      (concatMapStringsSep "\n" (p: p) (meta.packages or []));
   in
     header + "\n" + explicit + optionalString (packages != "") ("\n" + packages);
 
+  # Module paths whose source must be copied rather than symlinked. Before
+  # Go 1.25, //go:embed rejects symlinked files, so modules govendor recorded
+  # as embedding files are copied. Every module is copied when the manifest
+  # predates govendor recording embed usage.
+  copiedModules = go: manifest: modules:
+    if lib.versionAtLeast go.version "1.25"
+    then []
+    else if builtins.elem "embed" (manifest.features or [])
+    then builtins.attrNames (lib.filterAttrs (_: meta: meta.embed or false) modules)
+    else builtins.attrNames modules;
+
+  # Warn when cgo is disabled but modules recorded by govendor use it, as
+  # their packages fail to build unless they have a pure Go fallback.
+  warnCgoDisabled = CGO_ENABLED: modules: let
+    cgoModules = builtins.attrNames (lib.filterAttrs (_: meta: meta.cgo or false) modules);
+  in
+    lib.warnIf (toString CGO_ENABLED == "0" && cgoModules != [])
+    "go-overlay: CGO_ENABLED=0 but these modules use cgo and may fail to build: ${concatStringsSep ", " cgoModules}";
+
+  # Fail evaluation when modules govendor marked private would be fetched
+  # without GOPRIVATE (or GONOPROXY), as the go command would then ask the
+  # proxy for them and fail with an authentication error mid-build. Missing
+  # credentials only warn, as not every private module needs them.
+  checkPrivateModules = {
+    modules,
+    netrcFile,
+    GOPRIVATE,
+    GONOPROXY,
+  }: let
+    private = lib.filterAttrs (_: meta: meta ? private) modules;
+    names = builtins.attrNames private;
+    patterns = lib.unique (lib.mapAttrsToList (_: meta: meta.private) private);
+    missing =
+      lib.optional (GOPRIVATE == "" && GONOPROXY == "") "GOPRIVATE"
+      ++ lib.optional (netrcFile == null) "netrcFile";
+  in
+    if names != [] && GOPRIVATE == "" && GONOPROXY == ""
+    then
+      throw ''
+        go-overlay: private modules cannot be fetched without ${concatStringsSep " and " missing} set:
+          ${concatStringsSep "\n  " names}
+
+        govendor matched them against ${concatStringsSep ", " patterns}. Set:
+          GOPRIVATE = "${concatStringsSep "," patterns}";${optionalString (netrcFile == null) "\n  netrcFile = ./path/to/.netrc;"}
+      ''
+    else lib.warnIf (names != [] && netrcFile == null) "go-overlay: netrcFile is not set, private modules may fail to fetch: ${concatStringsSep ", " names}";
+
   # Generate shell commands to copy fetched modules into $out directory.
   # Handles overlapping module paths by processing deepest paths first and
-  # using symlinks where possible for performance.
+  # symlinking every module not listed in copied for performance.
   mkModuleCopyCommands = {
     sources,
-    useSymlinks ? true,
+    copied ? [],
   }: let
     pkgPaths = builtins.attrNames sources;
     # Lexicographic sort puts prefix paths before longer paths within the same domain.
@@ -48,7 +107,7 @@
 
           pkgPath = escapeShellArg goPackagePath;
         in
-          if useSymlinks
+          if !(builtins.elem goPackagePath copied)
           then ''
             pkg_path=${pkgPath}
 
@@ -75,7 +134,7 @@
     shopt -u dotglob
   '';
 in {
-  inherit mkModuleCopyCommands;
+  inherit isExcluded copiedModules warnCgoDisabled checkPrivateModules mkModuleCopyCommands;
 
   mkVendorEnv = {
     go,
@@ -86,39 +145,59 @@ in {
     GOPRIVATE ? "",
     GONOSUMDB ? "",
     GONOPROXY ? "",
+    excludeScopes ? [], # Scopes ("test", "tool") whose modules are not vendored
+    CGO_ENABLED ? null, # Warns about modules that use cgo when "0"
   }: let
     useSymlinks = lib.versionAtLeast go.version "1.25";
     modules = manifest.mod or {};
+    vendoredModules = lib.filterAttrs (_: meta: !(isExcluded excludeScopes meta)) modules;
+    copied = copiedModules go manifest vendoredModules;
 
-    remoteModules = lib.filterAttrs (_: meta: !(meta ? local)) modules;
-    localModules = lib.filterAttrs (_: meta: meta ? local) modules;
+    # Modules recorded without a hash (govendor --omit-unused-sources)
+    # contribute no packages, so only their modules.txt entry is written.
+    remoteModules = lib.filterAttrs (_: meta: !(meta ? local) && meta ? hash) vendoredModules;
+    localModules = lib.filterAttrs (_: meta: meta ? local) vendoredModules;
 
     # For remote path replacements (replace A => B version), govendor hashes the
     # replacement module B, so we must fetch B — not A — to match the stored hash.
+    # Modules with a recorded proxy zip URL are fetched without a Go toolchain.
     sources =
       builtins.mapAttrs (
-        goPackagePath: meta:
-          fetchGoModule {
-            goPackagePath =
-              if (meta ? replaced) && meta.replaced != goPackagePath
-              then meta.replaced
-              else goPackagePath;
-            inherit go netrcFile GOPRIVATE GONOSUMDB GONOPROXY;
-            inherit (meta) version hash;
-          }
+        goPackagePath: meta: let
+          fetchPath =
+            if (meta ? replaced) && meta.replaced != goPackagePath
+            then meta.replaced
+            else goPackagePath;
+        in
+          if meta ? url
+          then
+            fetchGoModuleZip {
+              goPackagePath = fetchPath;
+              inherit (meta) version hash url;
+              zipHash = meta.zip_hash;
+            }
+          else
+            fetchGoModule {
+              goPackagePath = fetchPath;
+              inherit go netrcFile GOPRIVATE GONOSUMDB GONOPROXY;
+              inherit (meta) version hash;
+            }
       )
       remoteModules;
 
     modulesTxt = let
       moduleEntries = concatMapStringsSep "\n" (
-        goPackagePath: mkModuleEntry goPackagePath modules.${goPackagePath}
+        goPackagePath: mkModuleEntry excludeScopes goPackagePath modules.${goPackagePath}
       ) (builtins.attrNames modules);
 
+      # Replacements are recorded for every module, vendored or not, as the
+      # go command checks them against go.mod.
+      localReplaceModules = lib.filterAttrs (_: meta: meta ? local) modules;
       localTrailers = concatMapStringsSep "\n" (
         goPackagePath: let
-          meta = localModules.${goPackagePath};
+          meta = localReplaceModules.${goPackagePath};
         in "# ${goPackagePath} => ${meta.local}"
-      ) (builtins.attrNames localModules);
+      ) (builtins.attrNames localReplaceModules);
 
       remoteReplaceModules = lib.filterAttrs (goPackagePath: meta: (meta ? replaced) && meta.replaced != goPackagePath) modules;
       remoteTrailers = concatMapStringsSep "\n" (
@@ -131,7 +210,7 @@ in {
       + optionalString (localTrailers != "") ("\n" + localTrailers)
       + optionalString (remoteTrailers != "") ("\n" + remoteTrailers);
 
-    remoteCopyCommands = mkModuleCopyCommands {inherit sources useSymlinks;};
+    remoteCopyCommands = mkModuleCopyCommands {inherit sources copied;};
 
     localModuleSources =
       builtins.mapAttrs (
@@ -143,30 +222,37 @@ in {
           else throw "go-overlay: Local module '${goPackagePath}' not found in localReplaces and no 'src' provided"
       )
       localModules;
-  in
-    runCommand "vendor-env"
-    {
-      passAsFile = ["modulesTxt"];
-      inherit modulesTxt;
-      passthru = {inherit sources useSymlinks;};
-      # Add localReplaces paths as explicit derivation inputs so they're tracked
-      # and fetched before the build runs (fixes CI builds where store paths
-      # don't exist yet)
-      localReplaceSrcs = lib.attrValues localReplaces;
-    }
-    ''
-      mkdir -p $out
 
-      # Copy remote modules
-      ${remoteCopyCommands}
+    vendorEnv =
+      runCommand "vendor-env"
+      {
+        passAsFile = ["modulesTxt"];
+        inherit modulesTxt;
+        passthru = {inherit sources useSymlinks;};
+        # Add localReplaces paths as explicit derivation inputs so they're tracked
+        # and fetched before the build runs (fixes CI builds where store paths
+        # don't exist yet)
+        localReplaceSrcs = lib.attrValues localReplaces;
+      }
+      ''
+        mkdir -p $out
 
-      # Copy local modules from source tree
-      ${mkModuleCopyCommands {
-        sources = localModuleSources;
-        inherit useSymlinks;
-      }}
+        # Copy remote modules
+        ${remoteCopyCommands}
 
-      # Write modules.txt
-      cp "$modulesTxtPath" "$out/modules.txt"
-    '';
+        # Copy local modules from source tree
+        ${mkModuleCopyCommands {
+          sources = localModuleSources;
+          inherit copied;
+        }}
+
+        # Write modules.txt
+        cp "$modulesTxtPath" "$out/modules.txt"
+      '';
+  in
+    checkPrivateModules {
+      modules = remoteModules;
+      inherit netrcFile GOPRIVATE GONOPROXY;
+    }
+    (warnCgoDisabled CGO_ENABLED vendoredModules vendorEnv);
 }
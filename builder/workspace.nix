@@ -6,6 +6,11 @@
   stdenv,
   runCommand,
   fetchGoModule,
+  fetchGoModuleZip,
+  isExcluded,
+  copiedModules,
+  warnCgoDisabled,
+  checkPrivateModules,
   mkModuleCopyCommands,
   mkHostTool,
   parseGoWorkModules,
@@ -27,19 +32,25 @@
   in
     header + "\n" + explicit;
 
+  # Packages of a module listed in modules.txt, none when its source is not
+  # vendored.
+  vendoredPackages = excludeScopes: meta:
+    optionalString (!isExcluded excludeScopes meta)
+    (concatMapStringsSep "\n" (p: p) (meta.packages or []));
+
   # Generate modules.txt entry for a local-replace module (=> ./path format).
-  mkLocalEntry = modPath: meta: let
+  mkLocalEntry = excludeScopes: modPath: meta: let
     header = "# ${modPath} ${meta.version} => ${meta.local}";
     explicit =
       if meta.go or "" != ""
       then "## explicit; go ${meta.go}"
       else "## explicit";
-    packages = concatMapStringsSep "\n" (p: p) (meta.packages or []);
+    packages = vendoredPackages excludeScopes meta;
   in
     header + "\n" + explicit + optionalString (packages != "") ("\n" + packages);
 
   # Generate modules.txt entry for a remote module (standard format).
-  mkRemoteEntry = modPath: meta: let
+  mkRemoteEntry = excludeScopes: modPath: meta: let
     isRemoteReplace = (meta ? replaced) && meta.replaced != modPath;
     header =
       if isRemoteReplace
@@ -49,7 +60,7 @@
       if meta.go or "" != ""
       then "## explicit; go ${meta.go}"
       else "## explicit";
-    packages = concatMapStringsSep "\n" (p: p) (meta.packages or []);
+    packages = vendoredPackages excludeScopes meta;
   in
     header + "\n" + explicit + optionalString (packages != "") ("\n" + packages);
 in {
@@ -112,6 +123,11 @@ in {
     goWorkContent =
       "go ${workspaceConfig.go}\n"
       + optionalString (workspaceConfig.toolchain or "" != "") "toolchain ${workspaceConfig.toolchain}\n"
+      + optionalString (workspaceConfig ? godebug) (
+        "\ngodebug (\n"
+        + concatMapStringsSep "\n" (key: "\t${key}=${workspaceConfig.godebug.${key}}") (builtins.attrNames workspaceConfig.godebug)
+        + "\n)\n"
+      )
       + "\n"
       + "use (\n"
       + concatMapStringsSep "\n" (mod: "\t${mod}") (workspaceConfig.modules or [])
@@ -121,21 +137,33 @@ in {
     # from external local replaces in the manifest.
     workspaceMemberPaths = workspaceConfig.modules or [];
 
-    # Modules with hash are fetched; workspace deps have no hash;
-    # local replaces have a local field that is NOT a workspace member path.
-    remoteModules = lib.filterAttrs (_: meta: meta ? hash && meta.hash != "" && !(meta ? local)) allModules;
-    workspaceDepModules = lib.filterAttrs (_: meta: (!(meta ? hash) || meta.hash == "") && (!(meta ? local) || builtins.elem meta.local workspaceMemberPaths)) allModules;
+    # Modules with hash are fetched; workspace deps have no hash and a local
+    # field naming a workspace member; local replaces have a local field that
+    # is NOT a workspace member path. Remote modules recorded without a hash
+    # (govendor --omit-unused-sources) contribute no packages, so only their
+    # modules.txt entry is written.
+    remoteModules = lib.filterAttrs (_: meta: !(meta ? local)) allModules;
+    fetchedModules = lib.filterAttrs (_: meta: meta ? hash && meta.hash != "") remoteModules;
+    workspaceDepModules = lib.filterAttrs (_: meta: (!(meta ? hash) || meta.hash == "") && (meta ? local) && builtins.elem meta.local workspaceMemberPaths) allModules;
     localWorkspaceModules = lib.filterAttrs (_: meta: (meta ? local) && !(builtins.elem meta.local workspaceMemberPaths)) allModules;
 
     externalSources =
       builtins.mapAttrs (
         goPackagePath: meta:
-          fetchGoModule {
-            inherit goPackagePath go netrcFile GOPRIVATE GONOSUMDB GONOPROXY;
-            inherit (meta) version hash;
-          }
+          if meta ? url
+          then
+            fetchGoModuleZip {
+              inherit goPackagePath;
+              inherit (meta) version hash url;
+              zipHash = meta.zip_hash;
+            }
+          else
+            fetchGoModule {
+              inherit goPackagePath go netrcFile GOPRIVATE GONOSUMDB GONOPROXY;
+              inherit (meta) version hash;
+            }
       )
-      remoteModules;
+      fetchedModules;
 
     localModuleSources =
       builtins.mapAttrs (
@@ -155,16 +183,16 @@ in {
     #   ## explicit; go 1.18
     #   github.com/external/dep
     #   # github.com/external/local-lib => ../local-lib
-    modulesTxt =
+    mkModulesTxt = excludeScopes:
       "## workspace\n"
       + concatMapStringsSep "\n" (p: mkWorkspaceDepEntry p workspaceDepModules.${p}) (builtins.attrNames workspaceDepModules)
       + optionalString (localWorkspaceModules != {}) (
         "\n"
-        + concatMapStringsSep "\n" (p: mkLocalEntry p localWorkspaceModules.${p}) (builtins.attrNames localWorkspaceModules)
+        + concatMapStringsSep "\n" (p: mkLocalEntry excludeScopes p localWorkspaceModules.${p}) (builtins.attrNames localWorkspaceModules)
       )
       + optionalString (remoteModules != {}) (
         "\n"
-        + concatMapStringsSep "\n" (p: mkRemoteEntry p remoteModules.${p}) (builtins.attrNames remoteModules)
+        + concatMapStringsSep "\n" (p: mkRemoteEntry excludeScopes p remoteModules.${p}) (builtins.attrNames remoteModules)
       )
       + optionalString (localWorkspaceModules != {}) (
         "\n"
@@ -175,21 +203,27 @@ in {
 
     useSymlinks = lib.versionAtLeast go.version "1.25";
 
-    vendorEnv = (runCommand "workspace-vendor-env" {
+    # Builds the vendor directory, leaving out the source of modules needed
+    # only in excludeScopes ("test", "tool").
+    mkWorkspaceVendorEnv = excludeScopes: let
+      vendored = lib.filterAttrs (p: _: !(isExcluded excludeScopes allModules.${p}));
+      copied = copiedModules go manifest (vendored allModules);
+    in
+      (runCommand "workspace-vendor-env" {
         passAsFile = ["modulesTxt"];
-        inherit modulesTxt;
+        modulesTxt = mkModulesTxt excludeScopes;
         localReplaceSrcs = lib.attrValues localReplaces;
       } (
         ''
           mkdir -p $out
         ''
         + mkModuleCopyCommands {
-          sources = externalSources;
-          inherit useSymlinks;
+          sources = vendored externalSources;
+          inherit copied;
         }
         + mkModuleCopyCommands {
-          sources = localModuleSources;
-          inherit useSymlinks;
+          sources = vendored localModuleSources;
+          inherit copied;
         }
         + ''
 
@@ -199,6 +233,24 @@ in {
       ))
       .overrideAttrs (_: {passthru = {inherit useSymlinks;};});
 
+    # Tool-only modules are left out of the workspace's vendor tree, and
+    # test-only modules too when tests are not run. Tools are built from
+    # their own vendor tree, which only leaves out test-only modules.
+    vendorEnv = let
+      excludeScopes = ["tool"] ++ lib.optional (!(attrs.doCheck or true)) "test";
+    in
+      checkPrivateModules {
+        modules = lib.filterAttrs (_: meta: !(isExcluded excludeScopes meta)) fetchedModules;
+        inherit netrcFile GOPRIVATE GONOPROXY;
+      }
+      (warnCgoDisabled CGO_ENABLED
+        (lib.filterAttrs (_: meta: !(isExcluded excludeScopes meta)) allModules)
+        (mkWorkspaceVendorEnv excludeScopes));
+    toolVendorEnv = checkPrivateModules {
+      modules = lib.filterAttrs (_: meta: !(isExcluded ["test"] meta)) fetchedModules;
+      inherit netrcFile GOPRIVATE GONOPROXY;
+    } (mkWorkspaceVendorEnv ["test"]);
+
     configurePhase =
       attrs.configurePhase or ''
         runHook preConfigure
@@ -241,8 +293,8 @@ in {
     hostTools = map (pkg:
       mkHostTool {
         inherit src go pkg goWorkContent;
-        inherit (vendorEnv) useSymlinks;
-        vendorEnv = vendorEnv;
+        inherit (toolVendorEnv) useSymlinks;
+        vendorEnv = toolVendorEnv;
         version = manifest.tool.${pkg}.version;
         members = workspaceMemberPaths;
       })
@@ -15,6 +15,7 @@ import (
 	"github.com/purpleclay/conker/pool"
 	"github.com/purpleclay/go-overlay/internal/github"
 	"github.com/purpleclay/go-overlay/internal/manifest"
+	"github.com/purpleclay/go-overlay/internal/overlay"
 	"github.com/purpleclay/go-overlay/internal/scrape"
 	"github.com/purpleclay/go-overlay/internal/version"
 	"github.com/spf13/cobra"
@@ -130,7 +131,10 @@ func parse(page string, version string, date time.Time) (*Scrape, error) {
 }
 
 func newGenerateCmd() *cobra.Command {
-	var outputDir string
+	var (
+		outputDir    string
+		versionsFile string
+	)
 
 	cmd := &cobra.Command{
 		Use:   "generate [VERSIONS...]",
@@ -144,6 +148,10 @@ func newGenerateCmd() *cobra.Command {
 
 		The output format is compatible with go-overlay and uses Nix system identifiers
 		(e.g., x86_64-linux, aarch64-darwin) as keys.
+
+		With --versions-file, every version with a manifest in the output directory
+		is also listed in the given file, which govendor embeds to check that go.mod
+		selects a version go-overlay provides.
 		`,
 		Example: `
 		# Generate manifest for the latest available version and write to stdout
@@ -160,10 +168,17 @@ func newGenerateCmd() *cobra.Command {
 
        	# Generate manifests and write to directory
         goscrape go-dev generate 1.21.6 --output ./manifests/go
+
+        # Generate manifests and refresh the version list embedded by govendor
+        goscrape go-dev generate 1.21.6 --output ./manifests/go --versions-file ./internal/overlay/versions.txt
         `,
 		SilenceUsage:  true,
 		SilenceErrors: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if versionsFile != "" && outputDir == "" {
+				return fmt.Errorf("--versions-file requires --output")
+			}
+
 			page, ok := cmd.Context().Value(pageDataKey).(string)
 			if !ok {
 				return fmt.Errorf("failed to retrieve page data from context")
@@ -224,10 +239,24 @@ func newGenerateCmd() *cobra.Command {
 				}
 			}
 
+			if versionsFile != "" {
+				return writeVersions(outputDir, versionsFile)
+			}
 			return nil
 		},
 	}
 
 	cmd.Flags().StringVarP(&outputDir, "output", "o", "", "the directory path for writing generated .nix files")
+	cmd.Flags().StringVar(&versionsFile, "versions-file", "", "the file path for listing every version with a manifest in the output directory")
 	return cmd
 }
+
+// writeVersions lists every Go version with a manifest in dir, writing the
+// list to path for govendor to embed.
+func writeVersions(dir, path string) error {
+	versions, err := overlay.ScanManifests(dir)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, overlay.FormatVersions(versions), 0o644)
+}
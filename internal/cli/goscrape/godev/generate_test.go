@@ -2,6 +2,7 @@ package godev
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -22,3 +23,18 @@ func TestScrape(t *testing.T) {
 	manifest := s.String()
 	golden.Assert(t, manifest, "go1.21.6.nix.golden")
 }
+
+func TestWriteVersions(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"1.25.10.nix", "1.26rc1.nix", "1.25.9.nix", "1.26.0.nix", "index.nix"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("{}\n"), 0o644))
+	}
+
+	path := filepath.Join(t.TempDir(), "versions.txt")
+	require.NoError(t, writeVersions(dir, path))
+
+	got, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	golden.Assert(t, string(got), "versions.txt.golden")
+}
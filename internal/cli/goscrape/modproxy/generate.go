@@ -8,6 +8,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"slices"
@@ -96,8 +97,15 @@ func narHash(dir string) (string, error) {
 	return "sha256-" + base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
 }
 
-func downloadModule(module, ver string) (string, error) {
+func downloadModule(module, ver string, hermetic bool, cache *resolve.ModCache) (string, error) {
 	cmd := exec.Command("go", "mod", "download", "-json", module+"@"+ver)
+	cmd.Env = os.Environ()
+	if hermetic {
+		cmd.Env = resolve.HermeticEnviron(cmd.Env)
+	}
+	if cache != nil {
+		cmd.Env = append(cmd.Env, "GOMODCACHE="+cache.Dir)
+	}
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
@@ -114,6 +122,12 @@ func downloadModule(module, ver string) (string, error) {
 		return "", fmt.Errorf("failed to parse download output: %w", err)
 	}
 
+	if cache != nil {
+		if err := cache.Seal(result.Dir); err != nil {
+			return "", err
+		}
+	}
+
 	return result.Dir, nil
 }
 
@@ -122,14 +136,21 @@ func downloadModule(module, ver string) (string, error) {
 // rather than via os.Setenv, which is process-wide and unsafe under concurrent
 // goroutines.
 type modEnvExecutor struct {
+	exec    resolve.OSExecutor
 	baseEnv []string
 }
 
 func (e modEnvExecutor) Run(ctx context.Context, args []string, dir string, env []string) (string, error) {
-	return resolve.OSExecutor{}.Run(ctx, args, dir, append(e.baseEnv, env...))
+	return e.exec.Run(ctx, args, dir, append(e.baseEnv, env...))
+}
+
+// generateOptions control the environment tool manifests are generated in.
+type generateOptions struct {
+	hermetic bool
+	modCache *resolve.ModCache
 }
 
-func generateManifest(ctx context.Context, module, ver string, subPackages []string) (*toolManifest, error) {
+func generateManifest(ctx context.Context, module, ver string, subPackages []string, opts generateOptions) (*toolManifest, error) {
 	var (
 		info      *proxy.ModuleInfo
 		goVersion string
@@ -154,7 +175,7 @@ func generateManifest(ctx context.Context, module, ver string, subPackages []str
 
 	g.Go(func(_ context.Context) error {
 		var err error
-		srcDir, err = downloadModule(module, ver)
+		srcDir, err = downloadModule(module, ver, opts.hermetic, opts.modCache)
 		return err
 	})
 
@@ -184,7 +205,15 @@ func generateManifest(ctx context.Context, module, ver string, subPackages []str
 	// GOFLAGS=-mod=mod prevents Go from auto-enabling -mod=vendor when the
 	// downloaded module source contains an in-tree vendor directory; without
 	// it, go list fails with incomplete vendored dependencies.
-	resolver := resolve.New(modEnvExecutor{baseEnv: []string{"GOFLAGS=-mod=mod"}})
+	var resolverOpts []resolve.Option
+	if opts.modCache != nil {
+		resolverOpts = append(resolverOpts, resolve.WithModCache(opts.modCache))
+	}
+
+	resolver := resolve.New(modEnvExecutor{
+		exec:    resolve.OSExecutor{Hermetic: opts.hermetic},
+		baseEnv: []string{"GOFLAGS=-mod=mod"},
+	}, resolverOpts...)
 	deps, err := resolver.ResolveModule(ctx, goModFile, nixPlatforms)
 	if err != nil {
 		return nil, err
@@ -247,6 +276,9 @@ func newGenerateCmd() *cobra.Command {
 		outputDir       string
 		subPackages     []string
 		versionPatterns []string
+		hermetic        bool
+		isolatedCache   bool
+		modCacheDir     string
 	)
 
 	cmd := &cobra.Command{
@@ -268,6 +300,14 @@ func newGenerateCmd() *cobra.Command {
 		  --versions v1.1.3,v1.1.4 \
 		  --output manifests/govulncheck
 
+		# Generate a manifest without inheriting GOFLAGS or other shell settings
+		goscrape mod-proxy generate golang.org/x/vuln --sub-packages cmd/govulncheck \
+		  --hermetic
+
+		# Generate a manifest without touching the shared module cache
+		goscrape mod-proxy generate golang.org/x/vuln --sub-packages cmd/govulncheck \
+		  --isolated-modcache
+
 		# Generate manifests matching a glob pattern
 		goscrape mod-proxy generate golang.org/x/vuln --sub-packages cmd/govulncheck \
 		  --versions "v1.1*" \
@@ -284,11 +324,34 @@ func newGenerateCmd() *cobra.Command {
 				return err
 			}
 
+			if hermetic {
+				goEnv, err := resolve.GoEnv(cmd.Context(), resolve.OSExecutor{Hermetic: true})
+				if err != nil {
+					return err
+				}
+				resolve.PrintGoEnv(cmd.ErrOrStderr(), goEnv)
+			}
+
+			opts := generateOptions{hermetic: hermetic}
+			if isolatedCache || modCacheDir != "" {
+				var cache *resolve.ModCache
+				if modCacheDir != "" {
+					cache, err = resolve.OpenModCache(modCacheDir)
+				} else {
+					cache, err = resolve.NewTempModCache()
+				}
+				if err != nil {
+					return err
+				}
+				defer cache.Close()
+				opts.modCache = cache
+			}
+
 			p := pool.NewWithResults[*toolManifest]().WithMaxGoroutines(4).WithContext(cmd.Context())
 
 			for _, ver := range versions {
 				p.Go(func(ctx context.Context) (*toolManifest, error) {
-					return generateManifest(ctx, module, ver, subPackages)
+					return generateManifest(ctx, module, ver, subPackages, opts)
 				})
 			}
 
@@ -321,6 +384,11 @@ func newGenerateCmd() *cobra.Command {
 	cmd.Flags().StringSliceVar(&subPackages, "sub-packages", nil, "sub-packages to build (e.g. cmd/govulncheck)")
 	cmd.Flags().StringSliceVarP(&versionPatterns, "versions", "v", nil, "versions to generate, supports glob patterns (e.g. v1.1.3, \"v1.1*\")")
 
+	cmd.Flags().BoolVar(&hermetic, "hermetic", false, "run go with an allow-listed environment and print the effective go environment")
+	cmd.Flags().BoolVar(&isolatedCache, "isolated-modcache", false, "download into a fresh temporary module cache, removed on exit")
+	cmd.Flags().StringVar(&modCacheDir, "modcache", "", "download into the given module cache directory instead of GOMODCACHE")
+	cmd.MarkFlagsMutuallyExclusive("isolated-modcache", "modcache")
+
 	cmd.MarkFlagRequired("sub-packages")
 	return cmd
 }
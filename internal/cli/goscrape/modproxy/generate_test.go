@@ -9,7 +9,7 @@ import (
 )
 
 func TestGenerateManifest(t *testing.T) {
-	m, err := generateManifest(context.Background(), "golang.org/x/vuln", "v1.1.4", []string{"cmd/govulncheck"})
+	m, err := generateManifest(context.Background(), "golang.org/x/vuln", "v1.1.4", []string{"cmd/govulncheck"}, generateOptions{})
 	require.NoError(t, err)
 
 	golden.Assert(t, m.String(), "v1.1.4.nix.golden")
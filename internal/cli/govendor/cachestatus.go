@@ -0,0 +1,77 @@
+package govendor
+
+import (
+	"fmt"
+
+	"github.com/purpleclay/go-overlay/internal/nixcache"
+	"github.com/purpleclay/go-overlay/internal/ui"
+	"github.com/purpleclay/x/cli"
+	"github.com/spf13/cobra"
+)
+
+func newCacheStatusCmd() *cobra.Command {
+	var (
+		caches []string
+		output = cli.Enum(outputTable, outputTable, outputJSON)
+	)
+
+	cmd := &cobra.Command{
+		Use:   "cache-status [MANIFESTS...]",
+		Short: "Report which modules in a manifest can be substituted from a binary cache",
+		Long: `
+		Report which remote modules recorded in one or more govendor.toml manifests
+		can be substituted from a Nix binary cache, and which will be fetched from
+		the network during nix build.
+
+		The store path of each module's fetchGoModule derivation depends only on
+		its name and NAR hash, so it is computed without Nix and looked up in each
+		cache in turn. HTTP(S) and file:// caches are supported.
+		`,
+		Example: `
+		# Check ./govendor.toml against cache.nixos.org
+		govendor cache-status
+
+		# Check against a private cache, falling back to cache.nixos.org
+		govendor cache-status --cache https://cache.example.com --cache https://cache.nixos.org
+
+		# Check a cache exported with govendor binary-cache
+		govendor cache-status --cache file://$PWD/nix-cache
+		`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			modules, err := readModules(args)
+			if err != nil {
+				return err
+			}
+
+			statuses, err := nixcache.NewChecker(caches).Status(cmd.Context(), modules)
+			if err != nil {
+				return err
+			}
+
+			if output.Get() == outputJSON {
+				rendered, err := ui.RenderCacheStatusJSON(statuses)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), rendered)
+				return nil
+			}
+
+			cached := 0
+			for _, s := range statuses {
+				if s.Substitutable() {
+					cached++
+				}
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), ui.RenderCacheStatusTable(statuses))
+			fmt.Fprintf(cmd.OutOrStdout(), "%d of %d modules can be substituted, %d will be fetched\n", cached, len(statuses), len(statuses)-cached)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&caches, "cache", nil, "binary cache to query, in order of preference (default "+nixcache.DefaultCache+")")
+	cmd.Flags().VarP(output, "output", "o", "format used to render results")
+	return cmd
+}
@@ -0,0 +1,130 @@
+package govendor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/purpleclay/go-overlay/internal/export"
+	"github.com/purpleclay/go-overlay/internal/resolve"
+	"github.com/purpleclay/go-overlay/internal/vendor"
+	"github.com/purpleclay/x/cli"
+	"github.com/spf13/cobra"
+)
+
+// staleExportError reports an exported file that no longer matches its
+// manifest.
+type staleExportError struct {
+	file string
+}
+
+func (e *staleExportError) Error() string {
+	return fmt.Sprintf("%s is out of date, run 'govendor export' to regenerate", e.file)
+}
+
+func (e *staleExportError) drift() {}
+
+func newExportCmd() *cobra.Command {
+	var (
+		outputFile string
+		check      bool
+		format     = cli.Enum(export.FormatJSON, export.FormatBazel, export.FormatGomod2nix, export.FormatJSON)
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export [MANIFEST]",
+		Short: "Export a manifest for other build systems",
+		Long: `
+		Export a govendor.toml manifest in a format used by another build system or
+		tool, so the manifest stays the single source of truth:
+
+		  bazel      Gazelle go_repository rules, with the go.sum hash of every module
+		  gomod2nix  a gomod2nix.toml (schema 3), for builds not yet using go-overlay
+		  json       the manifest as JSON, for non-Nix tooling
+
+		Bazel rules need go.sum hashes, which are read from the go.sum files of the
+		module or workspace alongside the manifest.
+		`,
+		Example: `
+		# Print ./govendor.toml as JSON
+		govendor export
+
+		# Write Bazel go_repository rules
+		govendor export --format bazel --output deps.bzl
+
+		# Fail if gomod2nix.toml no longer matches the manifest
+		govendor export --format gomod2nix --output gomod2nix.toml --check
+		`,
+		Args:          cobra.MaximumNArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if check && outputFile == "" {
+				return fmt.Errorf("--check requires --output")
+			}
+
+			path := "."
+			if len(args) > 0 {
+				path = args[0]
+			}
+
+			manifestPath := manifestFile(path)
+			manifest, err := readManifest(manifestPath)
+			if err != nil {
+				return err
+			}
+
+			sums, err := resolve.ReadGoSums(goSumFiles(filepath.Dir(manifestPath), manifest)...)
+			if err != nil {
+				return err
+			}
+
+			data, err := export.Render(format.Get(), manifest, sums)
+			if err != nil {
+				return err
+			}
+
+			if outputFile == "" {
+				_, err := cmd.OutOrStdout().Write(data)
+				return err
+			}
+
+			if check {
+				existing, err := os.ReadFile(outputFile)
+				if err != nil && !errors.Is(err, os.ErrNotExist) {
+					return err
+				}
+				if !bytes.Equal(existing, data) {
+					return &staleExportError{file: outputFile}
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%s is up to date\n", outputFile)
+				return nil
+			}
+
+			if err := os.WriteFile(outputFile, data, 0o644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", outputFile, err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().Var(format, "format", "format to export the manifest in")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "file to write the export to, instead of stdout")
+	cmd.Flags().BoolVarP(&check, "check", "c", false, "fail if the --output file is out of date instead of writing it")
+	return cmd
+}
+
+// goSumFiles returns the go.sum files of the module or workspace in dir.
+func goSumFiles(dir string, manifest *vendor.Manifest) []string {
+	if manifest.Workspace == nil {
+		return []string{filepath.Join(dir, "go.sum")}
+	}
+
+	files := []string{filepath.Join(dir, "go.work.sum")}
+	for _, member := range manifest.Workspace.Modules {
+		files = append(files, filepath.Join(dir, member, "go.sum"))
+	}
+	return files
+}
@@ -0,0 +1,74 @@
+package govendor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/purpleclay/go-overlay/internal/goproxy"
+	"github.com/purpleclay/go-overlay/internal/mod"
+	"github.com/purpleclay/go-overlay/internal/vendor"
+)
+
+// manifestFile returns path, or the govendor.toml within it when path is a
+// directory.
+func manifestFile(path string) string {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return filepath.Join(path, "govendor.toml")
+	}
+	return path
+}
+
+// readManifest parses the govendor.toml at path, or within it when path is a
+// directory.
+func readManifest(path string) (*vendor.Manifest, error) {
+	path = manifestFile(path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	manifest, err := vendor.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return manifest, nil
+}
+
+// readModules returns every module recorded in the manifests at paths,
+// defaulting to the manifest in the current directory.
+func readModules(paths []string) ([]mod.ModuleConfig, error) {
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	var modules []mod.ModuleConfig
+	for _, path := range paths {
+		manifest, err := readManifest(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, cfg := range manifest.Mod {
+			modules = append(modules, cfg)
+		}
+	}
+	return modules, nil
+}
+
+// moduleSources returns the proxies modules are copied from: the download
+// cache within modCacheDir, followed by GOPROXY unless offline.
+func moduleSources(modCacheDir string, offline bool) ([]*goproxy.Client, error) {
+	cache, err := goproxy.New("file://" + filepath.Join(modCacheDir, "cache", "download"))
+	if err != nil {
+		return nil, err
+	}
+	if offline {
+		return []*goproxy.Client{cache}, nil
+	}
+
+	upstream, err := goproxy.FromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return []*goproxy.Client{cache, upstream}, nil
+}
@@ -0,0 +1,73 @@
+package govendor
+
+import (
+	"fmt"
+
+	"github.com/purpleclay/go-overlay/internal/mirror"
+	"github.com/spf13/cobra"
+)
+
+func newMirrorCmd() *cobra.Command {
+	var (
+		outputDir   string
+		modCacheDir string
+		offline     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "mirror [MANIFESTS...]",
+		Short: "Write a file-based GOPROXY containing every module in a manifest",
+		Long: `
+		Write a GOPROXY-compatible directory tree (@v/list, .info, .mod and .zip
+		files) for every remote module recorded in one or more govendor.toml
+		manifests. Pointing GOPROXY=file://DIR at the result reproduces the same
+		NAR hashes, for resolving or building without network access.
+
+		Modules are taken from the local module cache where possible, and from
+		GOPROXY otherwise. Every zip is verified against the NAR hash recorded in
+		its manifest before it is written. Mirroring into an existing directory
+		adds to it, keeping previously mirrored versions.
+		`,
+		Example: `
+		# Mirror the modules of ./govendor.toml into ./goproxy
+		govendor mirror --output ./goproxy
+
+		# Mirror several manifests into one directory, then resolve against it
+		govendor mirror --output /srv/goproxy ./api ./web/govendor.toml
+		GOPROXY=file:///srv/goproxy govendor --check ./api ./web
+
+		# Mirror only from the local module cache, never contacting GOPROXY
+		govendor mirror --output ./goproxy --offline
+		`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			modules, err := readModules(args)
+			if err != nil {
+				return err
+			}
+
+			clients, err := moduleSources(modCacheDir, offline)
+			if err != nil {
+				return err
+			}
+			sources := make([]mirror.Source, 0, len(clients))
+			for _, c := range clients {
+				sources = append(sources, c)
+			}
+
+			n, err := mirror.New(outputDir, sources...).Write(cmd.Context(), modules)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "mirrored %d modules into %s\n", n, outputDir)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputDir, "output", "o", "", "directory to write the GOPROXY tree into")
+	cmd.Flags().StringVar(&modCacheDir, "modcache", mirror.DefaultModCache(), "module cache to mirror modules from before trying GOPROXY")
+	cmd.Flags().BoolVar(&offline, "offline", false, "only mirror from the module cache, failing if a module is missing")
+	_ = cmd.MarkFlagRequired("output")
+	return cmd
+}
@@ -1,11 +1,18 @@
 package govendor
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"os"
+	"time"
 
+	"github.com/purpleclay/go-overlay/internal/goproxy"
+	"github.com/purpleclay/go-overlay/internal/mod"
+	"github.com/purpleclay/go-overlay/internal/overlay"
 	"github.com/purpleclay/go-overlay/internal/resolve"
+	"github.com/purpleclay/go-overlay/internal/trace"
 	"github.com/purpleclay/go-overlay/internal/ui"
 	"github.com/purpleclay/go-overlay/internal/vendor"
 	"github.com/purpleclay/x/cli"
@@ -16,8 +23,8 @@ import (
 // Exit code convention, matching gofmt / terraform fmt -check:
 //
 //	0: all manifests up to date / generated
-//	1: drift or missing manifest detected (--check)
-//	2: execution error (toolchain failure, parse error, bad flags)
+//	1: drift or missing manifest detected (--check), or a stale vendorHash or export
+//	2: execution error (toolchain failure, parse error, bad flags, timeout)
 //
 // Mixed results report the most severe code.
 const (
@@ -26,6 +33,36 @@ const (
 	exitError = 2
 )
 
+// outputFormat selects how results are rendered.
+type outputFormat string
+
+const (
+	outputTable outputFormat = "table"
+	outputJSON  outputFormat = "json"
+)
+
+// resolverKind selects how the module graph is resolved.
+type resolverKind string
+
+const (
+	resolverGo     resolverKind = "go"
+	resolverNative resolverKind = "native"
+)
+
+// platformResolver is a vendor.Resolver that can validate the platforms it
+// is asked to resolve for.
+type platformResolver interface {
+	vendor.Resolver
+	ValidatePlatforms(ctx context.Context, platforms []string) error
+}
+
+// driftError is implemented by subcommand errors that report a stale file,
+// which exit with exitDrift rather than exitError.
+type driftError interface {
+	error
+	drift()
+}
+
 // resultsExitCode returns the most severe exit code implied by results.
 // Callers only invoke this when VendorFiles has already returned a non-nil
 // error, which it only does when at least one result is a failure — the
@@ -34,7 +71,7 @@ const (
 func resultsExitCode(results []vendor.Result) int {
 	sawDrift := false
 	for _, r := range results {
-		if r.Status == vendor.StatusError {
+		if r.Status == vendor.StatusError || r.Status == vendor.StatusTimeout {
 			return exitError
 		}
 		if r.Status == vendor.StatusDrift || r.Status == vendor.StatusMissing {
@@ -54,6 +91,29 @@ func Execute(version cli.VersionInfo, args []string) (int, error) {
 		workspace        bool
 		depth            int
 		includePlatforms []string
+		verbose          bool
+		traceFile        string
+		retryAttempts    int
+		retryBackoff     time.Duration
+		timeout          time.Duration
+		pathTimeout      time.Duration
+		hermetic         bool
+		isolatedCache    bool
+		modCacheDir      string
+		goBin            string
+		strictToolchain  bool
+		goCompat         bool
+		goTarget         string
+		skipGoAvailable  bool
+		proxyZips        bool
+		zipURLs          bool
+		omitUnused       bool
+		packages         []string
+		allPackages      bool
+		private          []string
+		profile          string
+		output           = cli.Enum(outputTable, outputTable, outputJSON)
+		resolverMode     = cli.Enum(resolverGo, resolverGo, resolverNative)
 		tableRendered    bool
 		exitCode         int
 	)
@@ -73,6 +133,27 @@ func Execute(version cli.VersionInfo, args []string) (int, error) {
 		file is detected, a unified manifest is generated containing dependencies from
 		all workspace modules. As go.work files are typically added to a .gitignore file,
 		the workspace is reconstructed from the manifest when go.work is not present.
+
+		Resolution can be restricted to the packages a Nix build compiles with
+		--package, and --all-packages resolves every package again. A named
+		--profile writes its own govendor.<profile>.toml, always omitting unused
+		sources, so one module can back several builds, and every profile alongside
+		govendor.toml is regenerated or checked unless --profile selects one.
+
+		An alternate modfile, such as tools.mod, is resolved with -modfile when its
+		path is given, writing its manifest alongside it as tools.govendor.toml. Once
+		written, it is regenerated or checked with the go.mod next to it.
+
+		Modules matching a --private pattern are marked private in the manifest,
+		recording the pattern they matched, and listed against each result. Without
+		--private, a manifest recording no patterns is generated with those of
+		GOPRIVATE and GONOPROXY. The patterns are recorded too, and --check only
+		uses recorded patterns, so it never depends on GOPRIVATE.
+
+		The go or toolchain directive must select a Go version go-overlay provides,
+		as go-bin.fromGoMod does, or --check fails suggesting the nearest one. The
+		versions are those built into this govendor binary, so an older binary
+		may not know of a newer release; --skip-go-available skips the check.
 		`,
 		Example: `
 		# Generate vendor manifest for current directory
@@ -98,14 +179,113 @@ func Execute(version cli.VersionInfo, args []string) (int, error) {
 
 		# Include additional platforms for cross-compilation
 		govendor --include-platform=freebsd/amd64 --include-platform=openbsd/amd64
+
+		# Mark modules matching other patterns than GOPRIVATE as private
+		govendor --private "github.com/acme/*"
+
+		# Give up on any single path after 5 minutes, and on the whole run after 20
+		govendor --recursive --path-timeout 5m --timeout 20m
+
+		# Resolve with a controlled environment, ignoring GOFLAGS and other
+		# settings from the current shell
+		govendor --hermetic
+
+		# Resolve with the same Go toolchain the Nix build uses, failing if
+		# it does not match the go or toolchain directive in go.mod
+		govendor --go ~/sdk/go1.25.4/bin/go --strict-toolchain
+
+		# Fail if upgrading a dependency raised the Go version it requires
+		# beyond the toolchain the Nix build is pinned to
+		govendor --check --go-target go1.24.6
+
+		# Download and hash every module in a fresh module cache, removed on exit
+		govendor --isolated-modcache
+
+		# Resolve without the go command, fetching modules from GOPROXY
+		govendor --resolver native
+
+		# Hash modules straight from GOPROXY zips rather than extracting them
+		govendor --proxy-zips
+
+		# Record each module's proxy zip URL so Nix can fetch it without Go
+		govendor --zip-urls
+
+		# Skip fetching modules that only take part in version selection
+		govendor --omit-unused-sources
+
+		# Fetch them again, clearing the recorded setting
+		govendor --omit-unused-sources=false
+
+		# Resolve only the dependencies of the server command
+		govendor --package ./cmd/server
+
+		# Resolve every package again, clearing the recorded --package patterns
+		govendor --all-packages
+
+		# Write govendor.slim.toml for a slimmer build of the same module
+		govendor --profile slim --package ./cmd/server --package ./cmd/worker
+
+		# Write tools.govendor.toml for the tools declared in tools.mod
+		govendor tools.mod
+
+		# Write a file-based GOPROXY holding every module in the manifest
+		govendor mirror --output ./goproxy
+
+		# Export every module as a file-based Nix binary cache
+		govendor binary-cache --output ./nix-cache
+
+		# Report which modules a binary cache can substitute
+		govendor cache-status --cache https://cache.example.com
+
+		# Check the vendorHash of a nixpkgs buildGoModule package
+		govendor vendor-hash --check package.nix
+
+		# Export the manifest as Bazel go_repository rules
+		govendor export --format bazel --output deps.bzl
+
+		# Render results as JSON, including the class of any failure
+		govendor --check --output json
+
+		# Log every go and git invocation and export a Chrome trace of resolution
+		govendor --verbose --trace govendor-trace.json
 		`,
+		Args:          cobra.ArbitraryArgs,
 		SilenceUsage:  true,
 		SilenceErrors: true,
-		RunE: func(cmd *cobra.Command, args []string) error {
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
 			if workspace && !check {
 				return fmt.Errorf("--workspace requires --check")
 			}
 
+			if strictToolchain && goBin == "" {
+				return fmt.Errorf("--strict-toolchain requires --go")
+			}
+
+			if goTarget != "" {
+				goCompat = true
+				var err error
+				if goTarget, err = vendor.ParseToolchain(goTarget); err != nil {
+					return err
+				}
+			}
+
+			if goCompat && !check {
+				return fmt.Errorf("--go-compat requires --check")
+			}
+
+			// Recording zip URLs needs the zips fetched from the proxy.
+			if zipURLs {
+				proxyZips = true
+			}
+
+			if proxyZips && resolverMode.Get() == resolverNative {
+				return fmt.Errorf("--proxy-zips and --zip-urls are not supported by the native resolver")
+			}
+
+			if len(packages) > 0 && recursive {
+				return fmt.Errorf("--package cannot be used with --recursive")
+			}
+
 			var opts []vendor.Option
 
 			if len(args) > 0 {
@@ -124,24 +304,162 @@ func Execute(version cli.VersionInfo, args []string) (int, error) {
 				opts = append(opts, vendor.WithWorkspace())
 			}
 
-			resolver := resolve.New(resolve.OSExecutor{})
+			if pathTimeout > 0 {
+				opts = append(opts, vendor.WithPathTimeout(pathTimeout))
+			}
+
+			if cmd.Flags().Changed("omit-unused-sources") {
+				if profile != "" && !omitUnused {
+					return fmt.Errorf("--omit-unused-sources=false cannot be used with --profile, as a profile always omits unused sources")
+				}
+				opts = append(opts, vendor.WithOmitUnusedSources(omitUnused))
+			}
+
+			if len(packages) > 0 {
+				opts = append(opts, vendor.WithPackages(packages...))
+			}
+
+			if allPackages {
+				opts = append(opts, vendor.WithAllPackages())
+			}
+
+			if profile != "" {
+				if err := vendor.ValidateProfile(profile); err != nil {
+					return err
+				}
+				opts = append(opts, vendor.WithProfile(profile))
+			}
+
+			if goCompat {
+				opts = append(opts, vendor.WithGoVersionCheck(goTarget))
+			}
+
+			if !skipGoAvailable {
+				opts = append(opts, vendor.WithAvailableVersions(overlay.Versions()))
+			}
+
+			if len(private) > 0 {
+				opts = append(opts, vendor.WithPrivatePatterns(mod.ParsePrivatePatterns(private...)))
+			} else if envPrivate := mod.PrivatePatternsFromEnv(); len(envPrivate) > 0 {
+				opts = append(opts, vendor.WithDefaultPrivatePatterns(envPrivate))
+			}
+
+			ctx := cmd.Context()
+			if timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+
+			if retryAttempts < 1 {
+				return fmt.Errorf("--retry-attempts must be at least 1")
+			}
+
+			var (
+				exec         = newExecutor(hermetic, goBin)
+				resolverOpts []resolve.Option
+				recorder     *trace.Recorder
+			)
+
+			if goBin != "" {
+				goVersion, err := resolve.ToolchainVersion(ctx, exec)
+				if err != nil {
+					return err
+				}
+				opts = append(opts, vendor.WithToolchainVersion(goVersion))
+				if strictToolchain {
+					opts = append(opts, vendor.WithStrictToolchain())
+				}
+			}
+
+			if hermetic {
+				goEnv, err := resolve.GoEnv(ctx, exec)
+				if err != nil {
+					return err
+				}
+				resolve.PrintGoEnv(cmd.ErrOrStderr(), goEnv)
+			}
+
+			if verbose {
+				log := resolve.NewLogger(cmd.ErrOrStderr())
+				exec = resolve.VerboseExecutor{Exec: exec, Log: log}
+				resolverOpts = append(resolverOpts, resolve.WithLogger(log))
+			}
+
+			// Retries wrap the verbose executor so every attempt is logged.
+			exec = resolve.RetryExecutor{Exec: exec, Attempts: retryAttempts, Backoff: retryBackoff}
+
+			if isolatedCache || modCacheDir != "" {
+				cache, openErr := openModCache(modCacheDir)
+				if openErr != nil {
+					return openErr
+				}
+				defer func() {
+					if closeErr := cache.Close(); err == nil {
+						err = closeErr
+					}
+				}()
+				if err := cache.Validate(ctx, exec); err != nil {
+					return err
+				}
+				resolverOpts = append(resolverOpts, resolve.WithModCache(cache))
+			}
+
+			if traceFile != "" {
+				recorder = trace.New()
+				resolverOpts = append(resolverOpts, resolve.WithTrace(recorder))
+			}
+
+			var fetch *goproxy.Client
+			if proxyZips || resolverMode.Get() == resolverNative {
+				var err error
+				if fetch, err = goproxy.FromEnv(); err != nil {
+					return err
+				}
+			}
+
+			var resolver platformResolver
+			if resolverMode.Get() == resolverNative {
+				resolver = resolve.NewNative(fetch, exec, resolverOpts...)
+			} else {
+				if proxyZips {
+					resolverOpts = append(resolverOpts, resolve.WithProxy(fetch))
+				}
+				if zipURLs {
+					resolverOpts = append(resolverOpts, resolve.WithZipURLs())
+				}
+				resolver = resolve.New(exec, resolverOpts...)
+			}
 
 			if len(includePlatforms) > 0 {
-				if err := resolver.ValidatePlatforms(cmd.Context(), includePlatforms); err != nil {
+				if err := resolver.ValidatePlatforms(ctx, includePlatforms); err != nil {
 					return err
 				}
 				opts = append(opts, vendor.WithIncludePlatforms(includePlatforms))
 			}
 
 			v := vendor.NewVendor(resolver, opts...)
-			results, err := v.VendorFiles(cmd.Context())
+			results, err := v.VendorFiles(ctx)
+
 			if len(results) > 0 {
+				rendered, rerr := renderResults(results, output.Get())
+				if rerr != nil {
+					return rerr
+				}
 				tableRendered = true
-				fmt.Fprintln(cmd.OutOrStdout(), ui.RenderResultsTable(results))
+				fmt.Fprintln(cmd.OutOrStdout(), rendered)
 			}
 			if err != nil {
 				exitCode = resultsExitCode(results)
 			}
+
+			// The trace is written even when resolution fails, as slow or
+			// failing runs are exactly the ones worth inspecting.
+			if recorder != nil {
+				if terr := writeTrace(recorder, traceFile); terr != nil && err == nil {
+					return terr
+				}
+			}
 			return err
 		},
 	}
@@ -151,14 +469,42 @@ func Execute(version cli.VersionInfo, args []string) (int, error) {
 	cmd.Flags().BoolVarP(&workspace, "workspace", "w", false, "reverse scan from a submodule path for a govendor.toml containing a workspace manifest (requires --check)")
 	cmd.Flags().IntVarP(&depth, "depth", "d", 0, "limit directory traversal depth (0 = unlimited)")
 	cmd.Flags().StringArrayVar(&includePlatforms, "include-platform", nil, "extend platform list for dependency resolution (e.g., freebsd/amd64)")
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "log every go and git invocation and NAR hash timing to stderr")
+	cmd.Flags().StringVar(&traceFile, "trace", "", "write a Chrome trace-event file covering resolution phases")
+	cmd.Flags().VarP(output, "output", "o", "format used to render results")
+	cmd.Flags().Var(resolverMode, "resolver", "resolve with the go command, or natively from GOPROXY without one")
+	cmd.Flags().BoolVar(&proxyZips, "proxy-zips", false, "hash modules from GOPROXY zips without extracting them, honouring GONOPROXY and GOPRIVATE")
+	cmd.Flags().BoolVar(&zipURLs, "zip-urls", false, "record the proxy zip URL and hash of each module so Nix fetches it with fetchurl (implies --proxy-zips, kept for unchanged modules)")
+	cmd.Flags().StringArrayVar(&packages, "package", nil, "resolve only the packages matching a pattern, instead of ./... (recorded in the manifest)")
+	cmd.Flags().BoolVar(&allPackages, "all-packages", false, "resolve every package again, clearing the patterns recorded by --package")
+	cmd.Flags().StringArrayVar(&private, "private", nil, "mark modules matching a GOPRIVATE style pattern, or comma-separated list, as private (recorded in the manifest)")
+	cmd.Flags().StringVar(&profile, "profile", "", "generate or check only govendor.<profile>.toml, a manifest for a named set of --package patterns")
+	cmd.Flags().BoolVar(&omitUnused, "omit-unused-sources", false, "omit the source of modules that contribute no packages, so Nix never fetches them (recorded in the manifest, =false clears it)")
+	cmd.Flags().BoolVar(&hermetic, "hermetic", false, "run go and git with an allow-listed environment and print the effective go environment")
+	cmd.Flags().BoolVar(&isolatedCache, "isolated-modcache", false, "resolve against a fresh temporary module cache, removed on exit")
+	cmd.Flags().StringVar(&modCacheDir, "modcache", "", "resolve against the given module cache directory instead of GOMODCACHE, which must be writable and kept read-only (no -modcacherw in GOFLAGS)")
+	cmd.Flags().StringVar(&goBin, "go", "", "resolve with the given go binary, never switching toolchains, and warn if its version differs from go.mod")
+	cmd.Flags().BoolVar(&strictToolchain, "strict-toolchain", false, "fail instead of warn when the --go toolchain version differs from go.mod or the workspace")
+	cmd.Flags().BoolVar(&goCompat, "go-compat", false, "with --check, fail when a module requires a newer Go than the go or toolchain directive, or --go-target")
+	cmd.Flags().StringVar(&goTarget, "go-target", "", "Go toolchain, such as go1.24.6, modules must build with (implies --go-compat, defaults to the --go version)")
+	cmd.Flags().BoolVar(&skipGoAvailable, "skip-go-available", false, "skip checking the go or toolchain directive against the Go versions built into this govendor binary")
+	cmd.Flags().DurationVar(&timeout, "timeout", 0, "bound the total time spent across all paths (0 = no limit)")
+	cmd.Flags().DurationVar(&pathTimeout, "path-timeout", 0, "bound the time spent on each go.mod or go.work (0 = no limit)")
+	cmd.Flags().IntVar(&retryAttempts, "retry-attempts", resolve.DefaultRetryAttempts, "total attempts for go commands failing with transient network errors (1 = no retries)")
+	cmd.Flags().DurationVar(&retryBackoff, "retry-backoff", resolve.DefaultRetryBackoff, "initial delay between retries, doubled on each attempt with jitter")
 	cmd.MarkFlagsMutuallyExclusive("recursive", "workspace")
+	cmd.MarkFlagsMutuallyExclusive("isolated-modcache", "modcache")
+	cmd.MarkFlagsMutuallyExclusive("package", "all-packages")
+	cmd.MarkFlagsMutuallyExclusive("profile", "all-packages")
+	cli.BindEnv(cmd.Flags().Lookup("go"), "GOVENDOR_GO")
+	cmd.AddCommand(newMirrorCmd(), newBinaryCacheCmd(), newCacheStatusCmd(), newVendorHashCmd(), newExportCmd())
 	cmd.SetArgs(args)
 
 	cli.ExitCodes(
 		cmd,
 		cli.ExitCode{Code: exitOK, Desc: "manifests up to date/generated"},
-		cli.ExitCode{Code: exitDrift, Desc: "drift or missing manifest detected (--check)"},
-		cli.ExitCode{Code: exitError, Desc: "execution error (toolchain failure, parse error, bad flags)"},
+		cli.ExitCode{Code: exitDrift, Desc: "drift or missing manifest detected (--check), or a stale vendorHash or export (vendor-hash --check, export --check)"},
+		cli.ExitCode{Code: exitError, Desc: "execution error (toolchain failure, parse error, bad flags, timeout)"},
 	)
 
 	err := cli.Execute(
@@ -181,7 +527,53 @@ func Execute(version cli.VersionInfo, args []string) (int, error) {
 	// assigned is an execution error, not a drift signal.
 	if err != nil && exitCode == exitOK {
 		exitCode = exitError
+
+		var drift driftError
+		if errors.As(err, &drift) {
+			exitCode = exitDrift
+		}
 	}
 
 	return exitCode, err
 }
+
+func renderResults(results []vendor.Result, format outputFormat) (string, error) {
+	if format == outputJSON {
+		return ui.RenderResultsJSON(results)
+	}
+	return ui.RenderResultsTable(results), nil
+}
+
+// newExecutor returns the executor go and git commands run with, using an
+// allow-listed environment when hermetic, and the given go binary when set.
+//
+//nolint:ireturn
+func newExecutor(hermetic bool, goBin string) resolve.Executor {
+	var exec resolve.Executor = resolve.OSExecutor{Hermetic: hermetic}
+	if goBin != "" {
+		exec = resolve.ToolchainExecutor{Exec: exec, Go: goBin}
+	}
+	return exec
+}
+
+// openModCache opens dir as the module cache, or creates a temporary one when
+// dir is empty.
+func openModCache(dir string) (*resolve.ModCache, error) {
+	if dir == "" {
+		return resolve.NewTempModCache()
+	}
+	return resolve.OpenModCache(dir)
+}
+
+func writeTrace(rec *trace.Recorder, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create trace file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := rec.WriteTo(f); err != nil {
+		return fmt.Errorf("failed to write trace file: %w", err)
+	}
+	return f.Close()
+}
@@ -2,6 +2,7 @@ package govendor_test
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
 	"testing"
 
@@ -88,6 +89,27 @@ func TestExecuteExitCodes(t *testing.T) {
 		require.Equal(t, 2, code)
 	})
 
+	t.Run("2_TimedOut", func(t *testing.T) {
+		dir := t.TempDir()
+		writeGoMod(t, filepath.Join(dir, "go.mod"), "1.22")
+
+		code, err := govendor.Execute(version, []string{"--timeout", "1ns", dir})
+		require.Error(t, err)
+		require.Equal(t, 2, code)
+	})
+
+	t.Run("2_StrictToolchainMismatch", func(t *testing.T) {
+		goBin, err := exec.LookPath("go")
+		require.NoError(t, err)
+
+		dir := t.TempDir()
+		writeGoMod(t, filepath.Join(dir, "go.mod"), "1.1")
+
+		code, err := govendor.Execute(version, []string{"--go", goBin, "--strict-toolchain", dir})
+		require.Error(t, err)
+		require.Equal(t, 2, code)
+	})
+
 	t.Run("2_MixedSeverityReportsMostSevere", func(t *testing.T) {
 		driftDir := t.TempDir()
 		writeGoMod(t, filepath.Join(driftDir, "go.mod"), "1.22")
@@ -102,4 +124,109 @@ func TestExecuteExitCodes(t *testing.T) {
 		require.Error(t, err)
 		require.Equal(t, 2, code)
 	})
+
+	t.Run("0_SkipGoAvailable", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example\n\ngo 1.22\n\ntoolchain go1.22.99\n"), 0o644))
+
+		_, err := govendor.Execute(version, []string{dir})
+		require.NoError(t, err)
+
+		code, err := govendor.Execute(version, []string{"--check", dir})
+		require.Error(t, err)
+		require.Equal(t, 2, code)
+
+		code, err = govendor.Execute(version, []string{"--check", "--skip-go-available", dir})
+		require.NoError(t, err)
+		require.Equal(t, 0, code)
+	})
+
+	t.Run("2_ModCacheWithModCacheRW", func(t *testing.T) {
+		t.Setenv("GOFLAGS", "-modcacherw")
+		dir := t.TempDir()
+		writeGoMod(t, filepath.Join(dir, "go.mod"), "1.22")
+
+		code, err := govendor.Execute(version, []string{"--modcache", t.TempDir(), dir})
+		require.ErrorContains(t, err, "GOFLAGS sets -modcacherw")
+		require.Equal(t, 2, code)
+
+		code, err = govendor.Execute(version, []string{"--hermetic", "--modcache", t.TempDir(), dir})
+		require.NoError(t, err)
+		require.Equal(t, 0, code)
+	})
+
+	t.Run("2_MirrorMissingManifest", func(t *testing.T) {
+		code, err := govendor.Execute(version, []string{"mirror", "--output", t.TempDir(), t.TempDir()})
+		require.Error(t, err)
+		require.Equal(t, 2, code)
+	})
+
+	t.Run("1_StaleVendorHash", func(t *testing.T) {
+		dir := t.TempDir()
+		writeGoMod(t, filepath.Join(dir, "go.mod"), "1.22")
+		nixFile := filepath.Join(dir, "package.nix")
+		require.NoError(t, os.WriteFile(nixFile, []byte(`{ vendorHash = "sha256-AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="; }`), 0o644))
+
+		code, err := govendor.Execute(version, []string{"vendor-hash", "--check", nixFile, dir})
+		require.ErrorContains(t, err, "but vendoring produces null")
+		require.Equal(t, 1, code)
+
+		require.NoError(t, os.WriteFile(nixFile, []byte(`{ vendorHash = null; }`), 0o644))
+		code, err = govendor.Execute(version, []string{"vendor-hash", "--check", nixFile, dir})
+		require.NoError(t, err)
+		require.Equal(t, 0, code)
+	})
+
+	t.Run("1_StaleVendorHashHermeticIsolated", func(t *testing.T) {
+		dir := t.TempDir()
+		writeGoMod(t, filepath.Join(dir, "go.mod"), "1.22")
+		nixFile := filepath.Join(dir, "package.nix")
+		require.NoError(t, os.WriteFile(nixFile, []byte(`{ vendorHash = "sha256-AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="; }`), 0o644))
+
+		code, err := govendor.Execute(version, []string{"vendor-hash", "--hermetic", "--isolated-modcache", "--check", nixFile, dir})
+		require.ErrorContains(t, err, "but vendoring produces null")
+		require.Equal(t, 1, code)
+	})
+
+	t.Run("1_FakeVendorHash", func(t *testing.T) {
+		dir := t.TempDir()
+		writeGoMod(t, filepath.Join(dir, "go.mod"), "1.22")
+		nixFile := filepath.Join(dir, "package.nix")
+		require.NoError(t, os.WriteFile(nixFile, []byte(`{ lib }: { vendorHash = lib.fakeHash; }`), 0o644))
+
+		code, err := govendor.Execute(version, []string{"vendor-hash", "--check", nixFile, dir})
+		require.ErrorContains(t, err, "vendorHash is lib.fakeHash, but vendoring produces null")
+		require.Equal(t, 1, code)
+	})
+
+	t.Run("2_NonLiteralVendorHash", func(t *testing.T) {
+		dir := t.TempDir()
+		writeGoMod(t, filepath.Join(dir, "go.mod"), "1.22")
+		nixFile := filepath.Join(dir, "package.nix")
+		require.NoError(t, os.WriteFile(nixFile, []byte(`{ hashes }: { vendorHash = hashes.app; }`), 0o644))
+
+		code, err := govendor.Execute(version, []string{"vendor-hash", "--check", nixFile, dir})
+		require.ErrorContains(t, err, "vendorHash is set to hashes.app, expected a string, null or lib.fakeHash")
+		require.Equal(t, 2, code)
+	})
+
+	t.Run("1_StaleExport", func(t *testing.T) {
+		dir := t.TempDir()
+		writeGoMod(t, filepath.Join(dir, "go.mod"), "1.22")
+		_, err := govendor.Execute(version, []string{dir})
+		require.NoError(t, err)
+
+		exported := filepath.Join(dir, "gomod2nix.toml")
+		code, err := govendor.Execute(version, []string{"export", "--format", "gomod2nix", "--output", exported, "--check", dir})
+		require.ErrorContains(t, err, "is out of date")
+		require.Equal(t, 1, code)
+
+		code, err = govendor.Execute(version, []string{"export", "--format", "gomod2nix", "--output", exported, dir})
+		require.NoError(t, err)
+		require.Equal(t, 0, code)
+
+		code, err = govendor.Execute(version, []string{"export", "--format", "gomod2nix", "--output", exported, "--check", dir})
+		require.NoError(t, err)
+		require.Equal(t, 0, code)
+	})
 }
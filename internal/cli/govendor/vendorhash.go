@@ -0,0 +1,154 @@
+package govendor
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+
+	"github.com/purpleclay/go-overlay/internal/resolve"
+	"github.com/purpleclay/x/cli"
+	"github.com/spf13/cobra"
+)
+
+// vendorHashAttr matches a vendorHash attribute, capturing its value.
+var vendorHashAttr = regexp.MustCompile(`vendorHash\s*=\s*([^;]*?)\s*;`)
+
+// vendorHashLiteral matches the values readVendorHash can compare: a string,
+// null, or the lib.fakeHash placeholder, which never matches.
+var vendorHashLiteral = regexp.MustCompile(`^(null|"[^"]*"|(pkgs\.)?lib\.fakeHash)$`)
+
+// vendorHashMismatchError reports a vendorHash in a Nix file that differs
+// from the computed one, which is reported as drift rather than an
+// execution error.
+type vendorHashMismatchError struct {
+	file string
+	got  string
+	want string
+}
+
+func (e *vendorHashMismatchError) Error() string {
+	return fmt.Sprintf("%s: vendorHash is %s, but vendoring produces %s", e.file, e.got, e.want)
+}
+
+func (e *vendorHashMismatchError) drift() {}
+
+func newVendorHashCmd() *cobra.Command {
+	var (
+		checkFile     string
+		goBin         string
+		hermetic      bool
+		isolatedCache bool
+		modCacheDir   string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "vendor-hash [PATH]",
+		Short: "Compute the vendorHash used by nixpkgs' buildGoModule",
+		Long: `
+		Compute the vendorHash nixpkgs' buildGoModule expects for a module or
+		workspace, by running go mod vendor (or go work vendor) into a temporary
+		directory and hashing the result exactly as Nix does. The module itself is
+		left untouched.
+
+		The contents of vendor/modules.txt depend on the Go version, so the hash
+		only matches a Nix build using the same Go toolchain. Pass --go to select
+		that toolchain explicitly. As when generating manifests, --hermetic,
+		--isolated-modcache and --modcache control the environment and module
+		cache go runs with.
+		`,
+		Example: `
+		# Print the vendorHash for the module in the current directory
+		govendor vendor-hash
+
+		# Fail if the vendorHash in package.nix is out of date
+		govendor vendor-hash --check package.nix
+
+		# Compute the hash with the Go toolchain the Nix build uses
+		govendor vendor-hash --go ~/sdk/go1.25.4/bin/go ./tools
+		`,
+		Args:          cobra.MaximumNArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			dir := "."
+			if len(args) > 0 {
+				dir = args[0]
+			}
+
+			exec := newExecutor(hermetic, goBin)
+			if isolatedCache || modCacheDir != "" {
+				cache, openErr := openModCache(modCacheDir)
+				if openErr != nil {
+					return openErr
+				}
+				defer func() {
+					if closeErr := cache.Close(); err == nil {
+						err = closeErr
+					}
+				}()
+				if err := cache.Validate(cmd.Context(), exec); err != nil {
+					return err
+				}
+				exec = cache.Wrap(exec)
+			}
+
+			hash, err := resolve.VendorHash(cmd.Context(), exec, dir)
+			if err != nil {
+				return err
+			}
+
+			want := "null"
+			if hash != "" {
+				want = strconv.Quote(hash)
+			}
+
+			if checkFile == "" {
+				fmt.Fprintf(cmd.OutOrStdout(), "vendorHash = %s;\n", want)
+				return nil
+			}
+
+			got, err := readVendorHash(checkFile)
+			if err != nil {
+				return err
+			}
+			if got != want {
+				return &vendorHashMismatchError{file: checkFile, got: got, want: want}
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s: vendorHash is up to date\n", checkFile)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&checkFile, "check", "", "fail if the vendorHash in the given Nix file differs")
+	cmd.Flags().StringVar(&goBin, "go", "", "vendor with the given go binary, never switching toolchains")
+	cmd.Flags().BoolVar(&hermetic, "hermetic", false, "run go with an allow-listed environment")
+	cmd.Flags().BoolVar(&isolatedCache, "isolated-modcache", false, "vendor from a fresh temporary module cache, removed on exit")
+	cmd.Flags().StringVar(&modCacheDir, "modcache", "", "vendor from the given module cache directory instead of GOMODCACHE")
+	cmd.MarkFlagsMutuallyExclusive("isolated-modcache", "modcache")
+	cli.BindEnv(cmd.Flags().Lookup("go"), "GOVENDOR_GO")
+	return cmd
+}
+
+// readVendorHash returns the value of the single vendorHash attribute in a
+// Nix file, either a quoted string, null or lib.fakeHash.
+func readVendorHash(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	matches := vendorHashAttr.FindAllSubmatch(data, -1)
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("%s: no vendorHash attribute found", path)
+	case 1:
+		value := string(matches[0][1])
+		if !vendorHashLiteral.MatchString(value) {
+			return "", fmt.Errorf("%s: vendorHash is set to %s, expected a string, null or lib.fakeHash", path, value)
+		}
+		return value, nil
+	default:
+		return "", fmt.Errorf("%s: found %d vendorHash attributes, expected one", path, len(matches))
+	}
+}
@@ -0,0 +1,48 @@
+package export_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/purpleclay/go-overlay/internal/export"
+	"github.com/purpleclay/go-overlay/internal/resolve"
+	"github.com/purpleclay/go-overlay/internal/vendor"
+	"github.com/stretchr/testify/require"
+	"gotest.tools/v3/golden"
+)
+
+func parseManifest(t *testing.T) *vendor.Manifest {
+	t.Helper()
+	data, err := os.ReadFile("testdata/govendor.toml")
+	require.NoError(t, err)
+	m, err := vendor.Parse(data)
+	require.NoError(t, err)
+	return m
+}
+
+func TestRender(t *testing.T) {
+	sums, err := resolve.ReadGoSums("testdata/go.sum")
+	require.NoError(t, err)
+
+	tests := []struct {
+		format export.Format
+		golden string
+	}{
+		{format: export.FormatBazel, golden: "deps.bzl.golden"},
+		{format: export.FormatGomod2nix, golden: "gomod2nix.toml.golden"},
+		{format: export.FormatJSON, golden: "manifest.json.golden"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			got, err := export.Render(tt.format, parseManifest(t), sums)
+			require.NoError(t, err)
+			golden.Assert(t, string(got), tt.golden)
+		})
+	}
+}
+
+func TestRenderBazelRequiresGoSumEntries(t *testing.T) {
+	_, err := export.Render(export.FormatBazel, parseManifest(t), resolve.GoSums{})
+	require.ErrorContains(t, err, "github.com/Masterminds/semver/v3@v3.3.1: missing go.sum entry")
+}
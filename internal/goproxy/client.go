@@ -0,0 +1,226 @@
+package goproxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+// DefaultGOPROXY is used when GOPROXY is unset, matching the go command.
+const DefaultGOPROXY = "https://proxy.golang.org,direct"
+
+// ErrNotFound is returned when no proxy in the list serves a module version.
+var ErrNotFound = errors.New("not found")
+
+// ErrDirect is returned for module versions that must be fetched directly
+// from version control, either because they match GONOPROXY or because the
+// proxy list fell through to "direct". Only the go command can fetch them.
+var ErrDirect = errors.New("must be fetched directly")
+
+// Special GOPROXY entries, matching the go command.
+const (
+	proxyDirect = "direct"
+	proxyOff    = "off"
+)
+
+type proxy struct {
+	url string
+
+	// fallBackOnError moves on to the next proxy after any error, not just
+	// a missing module version. Set for entries followed by "|".
+	fallBackOnError bool
+}
+
+// Client fetches module go.mod files and source archives using the GOPROXY
+// protocol (https://go.dev/ref/mod#goproxy-protocol). Both https:// and
+// file:// proxy URLs are supported, so a module cache's download directory
+// can be served directly.
+type Client struct {
+	proxies []proxy
+	noProxy string
+	http    *http.Client
+}
+
+type Option func(*Client)
+
+// WithNoProxy sets the GONOPROXY glob patterns of module path prefixes that
+// are never fetched through a proxy.
+func WithNoProxy(patterns string) Option {
+	return func(c *Client) {
+		c.noProxy = patterns
+	}
+}
+
+// New creates a Client from a GOPROXY value. Entries separated by commas
+// fall back to the next only when a module version is not found, while
+// entries separated by pipes fall back after any error. The "direct" and
+// "off" entries are honoured when reached.
+func New(goproxy string, opts ...Option) (*Client, error) {
+	if strings.TrimSpace(goproxy) == "" {
+		goproxy = DefaultGOPROXY
+	}
+
+	c := &Client{http: http.DefaultClient}
+	for rest := goproxy; rest != ""; {
+		var (
+			entry           string
+			fallBackOnError bool
+		)
+		if i := strings.IndexAny(rest, ",|"); i >= 0 {
+			entry, fallBackOnError = rest[:i], rest[i] == '|'
+			rest = rest[i+1:]
+		} else {
+			entry, rest = rest, ""
+		}
+
+		entry = strings.TrimSpace(entry)
+		switch entry {
+		case "":
+			continue
+		case proxyDirect, proxyOff:
+		default:
+			if _, err := url.Parse(entry); err != nil {
+				return nil, fmt.Errorf("invalid GOPROXY entry %q: %w", entry, err)
+			}
+			entry = strings.TrimSuffix(entry, "/")
+		}
+		c.proxies = append(c.proxies, proxy{url: entry, fallBackOnError: fallBackOnError})
+	}
+
+	if len(c.proxies) == 0 {
+		return nil, fmt.Errorf("GOPROXY=%s does not name a module proxy", goproxy)
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// FromEnv creates a Client from the GOPROXY and GONOPROXY environment
+// variables. As with the go command, GONOPROXY defaults to GOPRIVATE.
+func FromEnv() (*Client, error) {
+	noProxy, ok := os.LookupEnv("GONOPROXY")
+	if !ok {
+		noProxy = os.Getenv("GOPRIVATE")
+	}
+	return New(os.Getenv("GOPROXY"), WithNoProxy(noProxy))
+}
+
+// Info returns the JSON metadata of a module version.
+func (c *Client) Info(ctx context.Context, path, version string) ([]byte, error) {
+	rc, _, err := c.open(ctx, path, version, ".info")
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// GoMod returns the go.mod file of a module version.
+func (c *Client) GoMod(ctx context.Context, path, version string) ([]byte, error) {
+	rc, _, err := c.open(ctx, path, version, ".mod")
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// Zip opens the source archive of a module version. The caller must close it.
+func (c *Client) Zip(ctx context.Context, path, version string) (io.ReadCloser, error) {
+	rc, _, err := c.open(ctx, path, version, ".zip")
+	return rc, err
+}
+
+// ZipFrom opens the source archive of a module version like Zip, also
+// returning the URL of the proxy that served it.
+func (c *Client) ZipFrom(ctx context.Context, path, version string) (io.ReadCloser, string, error) {
+	return c.open(ctx, path, version, ".zip")
+}
+
+func (c *Client) open(ctx context.Context, path, version, ext string) (io.ReadCloser, string, error) {
+	escPath, err := module.EscapePath(path)
+	if err != nil {
+		return nil, "", err
+	}
+	escVersion, err := module.EscapeVersion(version)
+	if err != nil {
+		return nil, "", err
+	}
+	rel := escPath + "/@v/" + escVersion + ext
+
+	if module.MatchPrefixPatterns(c.noProxy, path) {
+		return nil, "", fmt.Errorf("%s@%s: matches GONOPROXY: %w", path, version, ErrDirect)
+	}
+
+	var (
+		errs     []string
+		notFound = true
+	)
+	for _, p := range c.proxies {
+		switch p.url {
+		case proxyDirect:
+			return nil, "", fmt.Errorf("%s@%s: %w", path, version, ErrDirect)
+		case proxyOff:
+			return nil, "", fmt.Errorf("%s@%s: module lookup disabled by GOPROXY=off", path, version)
+		}
+
+		target := p.url + "/" + rel
+		rc, err := c.get(ctx, target)
+		if err == nil {
+			return rc, target, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			if !p.fallBackOnError {
+				return nil, "", fmt.Errorf("%s@%s: %w", path, version, err)
+			}
+			notFound = false
+		}
+		errs = append(errs, err.Error())
+	}
+
+	if notFound {
+		return nil, "", fmt.Errorf("%s@%s: %s: %w", path, version, strings.Join(errs, "; "), ErrNotFound)
+	}
+	return nil, "", fmt.Errorf("%s@%s: %s", path, version, strings.Join(errs, "; "))
+}
+
+func (c *Client) get(ctx context.Context, target string) (io.ReadCloser, error) {
+	if dir, ok := strings.CutPrefix(target, "file://"); ok {
+		f, err := os.Open(filepath.FromSlash(dir))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("reading %s: 404 Not Found: %w", target, ErrNotFound)
+		}
+		return f, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", target, err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound, http.StatusGone:
+		resp.Body.Close()
+		return nil, fmt.Errorf("reading %s: %s: %w", target, resp.Status, ErrNotFound)
+	default:
+		resp.Body.Close()
+		return nil, fmt.Errorf("reading %s: %s", target, resp.Status)
+	}
+}
@@ -0,0 +1,192 @@
+package goproxy_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/purpleclay/go-overlay/internal/goproxy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func proxyServer(t *testing.T, files map[string]string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClientGoModEscapesModulePath(t *testing.T) {
+	srv := proxyServer(t, map[string]string{
+		"/github.com/!burnt!sushi/toml/@v/v1.6.0.mod": "module github.com/BurntSushi/toml\n",
+	})
+
+	client, err := goproxy.New(srv.URL)
+	require.NoError(t, err)
+
+	data, err := client.GoMod(context.Background(), "github.com/BurntSushi/toml", "v1.6.0")
+	require.NoError(t, err)
+	assert.Equal(t, "module github.com/BurntSushi/toml\n", string(data))
+}
+
+func TestClientFallsBackWhenNotFound(t *testing.T) {
+	empty := proxyServer(t, nil)
+	full := proxyServer(t, map[string]string{
+		"/example.com/a/@v/v1.0.0.mod": "module example.com/a\n",
+	})
+
+	client, err := goproxy.New(empty.URL + "," + full.URL)
+	require.NoError(t, err)
+
+	data, err := client.GoMod(context.Background(), "example.com/a", "v1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, "module example.com/a\n", string(data))
+}
+
+func TestClientReturnsErrNotFound(t *testing.T) {
+	srv := proxyServer(t, nil)
+
+	client, err := goproxy.New(srv.URL)
+	require.NoError(t, err)
+
+	_, err = client.GoMod(context.Background(), "example.com/a", "v1.0.0")
+	require.ErrorIs(t, err, goproxy.ErrNotFound)
+	assert.Contains(t, err.Error(), "404 Not Found")
+}
+
+func TestClientStopsOnServerError(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	t.Cleanup(failing.Close)
+	full := proxyServer(t, map[string]string{
+		"/example.com/a/@v/v1.0.0.mod": "module example.com/a\n",
+	})
+
+	client, err := goproxy.New(failing.URL + "," + full.URL)
+	require.NoError(t, err)
+
+	_, err = client.GoMod(context.Background(), "example.com/a", "v1.0.0")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, goproxy.ErrNotFound)
+	assert.Contains(t, err.Error(), "502 Bad Gateway")
+}
+
+func TestClientZipFromReportsServingProxy(t *testing.T) {
+	empty := proxyServer(t, nil)
+	full := proxyServer(t, map[string]string{
+		"/example.com/a/@v/v1.0.0.zip": "zip",
+	})
+
+	client, err := goproxy.New(empty.URL + "," + full.URL)
+	require.NoError(t, err)
+
+	rc, url, err := client.ZipFrom(context.Background(), "example.com/a", "v1.0.0")
+	require.NoError(t, err)
+	defer rc.Close()
+
+	assert.Equal(t, full.URL+"/example.com/a/@v/v1.0.0.zip", url)
+}
+
+func TestClientFileProxy(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "example.com", "a", "@v"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "example.com", "a", "@v", "v1.0.0.zip"), []byte("zip"), 0o644))
+
+	client, err := goproxy.New("file://" + dir)
+	require.NoError(t, err)
+
+	rc, err := client.Zip(context.Background(), "example.com/a", "v1.0.0")
+	require.NoError(t, err)
+	defer rc.Close()
+
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	assert.Equal(t, "zip", string(data))
+}
+
+func TestClientFallsBackOnErrorAfterPipe(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	t.Cleanup(failing.Close)
+	full := proxyServer(t, map[string]string{
+		"/example.com/a/@v/v1.0.0.mod": "module example.com/a\n",
+	})
+
+	client, err := goproxy.New(failing.URL + "|" + full.URL)
+	require.NoError(t, err)
+
+	data, err := client.GoMod(context.Background(), "example.com/a", "v1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, "module example.com/a\n", string(data))
+}
+
+func TestClientDirect(t *testing.T) {
+	srv := proxyServer(t, nil)
+
+	client, err := goproxy.New(srv.URL + ",direct")
+	require.NoError(t, err)
+
+	_, err = client.GoMod(context.Background(), "example.com/a", "v1.0.0")
+	require.ErrorIs(t, err, goproxy.ErrDirect)
+}
+
+func TestClientOff(t *testing.T) {
+	srv := proxyServer(t, nil)
+
+	client, err := goproxy.New(srv.URL + ",off")
+	require.NoError(t, err)
+
+	_, err = client.GoMod(context.Background(), "example.com/a", "v1.0.0")
+	require.ErrorContains(t, err, "module lookup disabled by GOPROXY=off")
+	assert.NotErrorIs(t, err, goproxy.ErrNotFound)
+}
+
+func TestClientNoProxy(t *testing.T) {
+	srv := proxyServer(t, map[string]string{
+		"/example.com/a/@v/v1.0.0.mod":      "module example.com/a\n",
+		"/corp.example.com/b/@v/v1.0.0.mod": "module corp.example.com/b\n",
+	})
+
+	client, err := goproxy.New(srv.URL, goproxy.WithNoProxy("corp.example.com,*.internal"))
+	require.NoError(t, err)
+
+	_, err = client.GoMod(context.Background(), "corp.example.com/b", "v1.0.0")
+	require.ErrorIs(t, err, goproxy.ErrDirect)
+	assert.Contains(t, err.Error(), "matches GONOPROXY")
+
+	_, err = client.GoMod(context.Background(), "example.com/a", "v1.0.0")
+	require.NoError(t, err)
+}
+
+func TestFromEnvDefaultsNoProxyToGOPRIVATE(t *testing.T) {
+	srv := proxyServer(t, nil)
+	t.Setenv("GOPROXY", srv.URL)
+	t.Setenv("GOPRIVATE", "corp.example.com")
+	t.Setenv("GONOPROXY", "")
+	require.NoError(t, os.Unsetenv("GONOPROXY"))
+
+	client, err := goproxy.FromEnv()
+	require.NoError(t, err)
+
+	_, err = client.GoMod(context.Background(), "corp.example.com/b", "v1.0.0")
+	require.ErrorIs(t, err, goproxy.ErrDirect)
+}
+
+func TestNewRequiresProxy(t *testing.T) {
+	_, err := goproxy.New(",")
+	require.ErrorContains(t, err, "does not name a module proxy")
+}
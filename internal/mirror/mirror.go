@@ -0,0 +1,344 @@
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/build"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/purpleclay/conker/pool"
+	"github.com/purpleclay/go-overlay/internal/goproxy"
+	"github.com/purpleclay/go-overlay/internal/mod"
+	"github.com/purpleclay/go-overlay/internal/resolve"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// Source serves module files using the GOPROXY protocol. *goproxy.Client
+// satisfies it, for both module caches and remote proxies.
+type Source interface {
+	Info(ctx context.Context, path, version string) ([]byte, error)
+	GoMod(ctx context.Context, path, version string) ([]byte, error)
+	Zip(ctx context.Context, path, version string) (io.ReadCloser, error)
+}
+
+// Mirror writes a file-based GOPROXY directory tree, which can be served
+// with GOPROXY=file://DIR. Every module is verified against the NAR hash
+// recorded in its manifest before it is written, so the mirror reproduces
+// the same hashes.
+type Mirror struct {
+	dir     string
+	sources []Source
+
+	mu       sync.Mutex
+	versions map[string][]string
+}
+
+// New creates a Mirror writing to dir. Each module is taken from the first
+// source that has it.
+func New(dir string, sources ...Source) *Mirror {
+	return &Mirror{
+		dir:      dir,
+		sources:  sources,
+		versions: make(map[string][]string),
+	}
+}
+
+// DefaultModCache returns the module cache used by the go command, taken
+// from GOMODCACHE or otherwise the first GOPATH entry.
+func DefaultModCache() string {
+	if dir := os.Getenv("GOMODCACHE"); dir != "" {
+		return dir
+	}
+	gopath, _, _ := strings.Cut(build.Default.GOPATH, string(filepath.ListSeparator))
+	return filepath.Join(gopath, "pkg", "mod")
+}
+
+// remoteModule is a module fetched from a proxy, keyed by the path it is
+// fetched from, which for a remote replacement is the replacement path. A
+// module recorded without a hash, as with omit_unused_sources, only takes
+// part in version selection, so only its go.mod and .info are mirrored.
+type remoteModule struct {
+	target module.Version
+	hash   string
+}
+
+// Write mirrors every remote module in modules, skipping local replacements
+// and workspace members, and returns how many were mirrored. Modules
+// recorded without a hash are mirrored without their zip. The go.mod
+// files of their requirements are mirrored too, so the go command can load
+// the module graph. The @v/list file of each module path is merged with any
+// versions already mirrored.
+func (m *Mirror) Write(ctx context.Context, modules []mod.ModuleConfig) (int, error) {
+	remote := make(map[module.Version]string)
+	for _, cfg := range modules {
+		if cfg.Local != "" {
+			continue
+		}
+
+		v := module.Version{Path: cfg.FetchPath(), Version: cfg.Version}
+		hash, ok := remote[v]
+		if ok && hash != "" && cfg.Hash != "" && hash != cfg.Hash {
+			return 0, fmt.Errorf("%s@%s: manifests record different hashes %s and %s", v.Path, v.Version, hash, cfg.Hash)
+		}
+		if !ok || hash == "" {
+			remote[v] = cfg.Hash
+		}
+	}
+
+	p := pool.NewWithResults[[]byte]().WithMaxGoroutines(8).WithContext(ctx)
+	for v, hash := range remote {
+		p.Go(func(ctx context.Context) ([]byte, error) {
+			return m.writeModule(ctx, remoteModule{target: v, hash: hash})
+		})
+	}
+	goMods, err := p.Wait()
+	if err != nil {
+		return 0, err
+	}
+
+	seen := make(map[module.Version]bool, len(remote))
+	for v := range remote {
+		seen[v] = true
+	}
+	if err := m.writeGraph(ctx, goMods, seen); err != nil {
+		return 0, err
+	}
+
+	if err := m.writeLists(); err != nil {
+		return 0, err
+	}
+	return len(remote), nil
+}
+
+// writeModule mirrors the zip, go.mod and .info files of a module, returning
+// its go.mod.
+func (m *Mirror) writeModule(ctx context.Context, rm remoteModule) ([]byte, error) {
+	base, err := m.base(rm.target)
+	if err != nil {
+		return nil, err
+	}
+	if rm.hash != "" {
+		if err := m.writeZip(ctx, rm, base+".zip"); err != nil {
+			return nil, err
+		}
+	}
+	return m.writeMetadata(ctx, rm.target, base)
+}
+
+// writeGraph mirrors the go.mod file of every module reachable through the
+// requirements in goMods. The go command loads these to build the module
+// graph, even for modules that provide no packages and so have no manifest
+// entry. Graph pruning means the go command may never have downloaded some of
+// them, so a go.mod missing from every source is skipped.
+func (m *Mirror) writeGraph(ctx context.Context, goMods [][]byte, seen map[module.Version]bool) error {
+	for len(goMods) > 0 {
+		var next []module.Version
+		for _, data := range goMods {
+			f, err := modfile.ParseLax("go.mod", data, nil)
+			if err != nil {
+				return err
+			}
+			for _, req := range f.Require {
+				if !seen[req.Mod] {
+					seen[req.Mod] = true
+					next = append(next, req.Mod)
+				}
+			}
+		}
+
+		p := pool.NewWithResults[[]byte]().WithMaxGoroutines(8).WithContext(ctx)
+		for _, v := range next {
+			p.Go(func(ctx context.Context) ([]byte, error) {
+				base, err := m.base(v)
+				if err != nil {
+					return nil, err
+				}
+				goMod, err := m.writeMetadata(ctx, v, base)
+				if errors.Is(err, goproxy.ErrNotFound) {
+					return nil, nil
+				}
+				return goMod, err
+			})
+		}
+
+		var err error
+		if goMods, err = p.Wait(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// base returns the path, without an extension, of the files mirrored for a
+// module version.
+func (m *Mirror) base(v module.Version) (string, error) {
+	escPath, err := module.EscapePath(v.Path)
+	if err != nil {
+		return "", err
+	}
+	escVersion, err := module.EscapeVersion(v.Version)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(m.dir, filepath.FromSlash(escPath), "@v", escVersion), nil
+}
+
+// writeMetadata mirrors the go.mod and .info files of a module, returning its
+// go.mod.
+func (m *Mirror) writeMetadata(ctx context.Context, v module.Version, base string) ([]byte, error) {
+	goMod, err := m.fetch(func(src Source) ([]byte, error) {
+		return src.GoMod(ctx, v.Path, v.Version)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFile(base+".mod", goMod); err != nil {
+		return nil, err
+	}
+
+	// A module cache may hold a version without its .info file, so a
+	// minimal one is written when no source has it.
+	info, err := m.fetch(func(src Source) ([]byte, error) {
+		return src.Info(ctx, v.Path, v.Version)
+	})
+	if errors.Is(err, goproxy.ErrNotFound) {
+		info = fmt.Appendf(nil, "{\"Version\":%q}\n", v.Version)
+	} else if err != nil {
+		return nil, err
+	}
+	if err := writeFile(base+".info", info); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	m.versions[v.Path] = append(m.versions[v.Path], v.Version)
+	m.mu.Unlock()
+	return goMod, nil
+}
+
+// writeZip fetches the zip of a module unless the mirror already holds one
+// with the expected NAR hash.
+func (m *Mirror) writeZip(ctx context.Context, rm remoteModule, path string) error {
+	if hash, err := resolve.NARHashZip(path, rm.target); err == nil && hash == rm.hash {
+		return nil
+	}
+
+	var lastErr error
+	for _, src := range m.sources {
+		rc, err := src.Zip(ctx, rm.target.Path, rm.target.Version)
+		if errors.Is(err, goproxy.ErrNotFound) {
+			lastErr = err
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		tmp, err := copyToTemp(filepath.Dir(path), rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("failed to download %s@%s: %w", rm.target.Path, rm.target.Version, err)
+		}
+
+		hash, err := resolve.NARHashZip(tmp, rm.target)
+		if err != nil {
+			os.Remove(tmp)
+			return err
+		}
+		if hash != rm.hash {
+			os.Remove(tmp)
+			return fmt.Errorf("%s@%s: zip hashes to %s, but the manifest records %s", rm.target.Path, rm.target.Version, hash, rm.hash)
+		}
+		return rename(tmp, path)
+	}
+	return lastErr
+}
+
+// fetch returns the result of the first source that has a module version.
+func (m *Mirror) fetch(get func(Source) ([]byte, error)) ([]byte, error) {
+	var lastErr error
+	for _, src := range m.sources {
+		data, err := get(src)
+		if errors.Is(err, goproxy.ErrNotFound) {
+			lastErr = err
+			continue
+		}
+		return data, err
+	}
+	return nil, lastErr
+}
+
+// writeLists writes the @v/list file of every mirrored module path, keeping
+// versions mirrored by earlier runs.
+func (m *Mirror) writeLists() error {
+	for path, versions := range m.versions {
+		escPath, err := module.EscapePath(path)
+		if err != nil {
+			return err
+		}
+		listPath := filepath.Join(m.dir, filepath.FromSlash(escPath), "@v", "list")
+
+		if data, err := os.ReadFile(listPath); err == nil {
+			versions = append(versions, strings.Fields(string(data))...)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		semver.Sort(versions)
+		versions = slices.Compact(versions)
+		if err := writeFile(listPath, []byte(strings.Join(versions, "\n")+"\n")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyToTemp copies r to a new temporary file in dir, creating dir if
+// needed.
+func copyToTemp(dir string, r io.Reader) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create mirror directory: %w", err)
+	}
+
+	f, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, r); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), f.Close()
+}
+
+// writeFile writes data to path through a temporary file, so an
+// interrupted run never leaves a partial file in the mirror.
+func writeFile(path string, data []byte) error {
+	tmp, err := copyToTemp(filepath.Dir(path), bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return rename(tmp, path)
+}
+
+// rename moves a temporary file into place, making it readable by anyone
+// serving the mirror.
+func rename(tmp, path string) error {
+	if err := os.Chmod(tmp, 0o644); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,168 @@
+package mirror_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/purpleclay/go-overlay/internal/goproxy"
+	"github.com/purpleclay/go-overlay/internal/mirror"
+	"github.com/purpleclay/go-overlay/internal/mod"
+	"github.com/purpleclay/go-overlay/internal/resolve"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/module"
+	modzip "golang.org/x/mod/zip"
+)
+
+// publish writes a module requiring requires to a file-based proxy
+// directory, returning its NAR hash.
+func publish(t *testing.T, proxyDir, path, version string, requires ...string) string {
+	t.Helper()
+	src := t.TempDir()
+	goMod := "module " + path + "\n\ngo 1.22\n"
+	for _, req := range requires {
+		goMod += "\nrequire " + req + "\n"
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(src, "go.mod"), []byte(goMod), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "lib.go"), []byte("package lib\n"), 0o644))
+
+	m := module.Version{Path: path, Version: version}
+	escPath, err := module.EscapePath(path)
+	require.NoError(t, err)
+	dir := filepath.Join(proxyDir, filepath.FromSlash(escPath), "@v")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+
+	zipFile := filepath.Join(dir, version+".zip")
+	f, err := os.Create(zipFile)
+	require.NoError(t, err)
+	require.NoError(t, modzip.CreateFromDir(f, m, src))
+	require.NoError(t, f.Close())
+	require.NoError(t, os.WriteFile(filepath.Join(dir, version+".mod"), []byte(goMod), 0o644))
+
+	hash, err := resolve.NARHashZip(zipFile, m)
+	require.NoError(t, err)
+	return hash
+}
+
+func fileProxy(t *testing.T, dir string) *goproxy.Client {
+	t.Helper()
+	client, err := goproxy.New("file://" + dir)
+	require.NoError(t, err)
+	return client
+}
+
+func TestWrite(t *testing.T) {
+	cache, upstream := t.TempDir(), t.TempDir()
+	hashA := publish(t, cache, "example.com/a", "v1.0.0")
+	hashB := publish(t, upstream, "example.com/Upper", "v0.2.0")
+
+	out := t.TempDir()
+	n, err := mirror.New(out, fileProxy(t, cache), fileProxy(t, upstream)).Write(context.Background(), []mod.ModuleConfig{
+		{Path: "example.com/a", Version: "v1.0.0", Hash: hashA},
+		{Path: "example.com/replaced", Version: "v0.2.0", Hash: hashB, ReplacedPath: "example.com/Upper"},
+		{Path: "example.com/local", Version: "v0.0.0", Hash: "sha256-local", Local: "./local"},
+		{Path: "example.com/member", Version: "v0.0.0", Local: "./member"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	for _, name := range []string{"v1.0.0.info", "v1.0.0.mod", "v1.0.0.zip"} {
+		assert.FileExists(t, filepath.Join(out, "example.com", "a", "@v", name))
+	}
+
+	list, err := os.ReadFile(filepath.Join(out, "example.com", "!upper", "@v", "list"))
+	require.NoError(t, err)
+	assert.Equal(t, "v0.2.0\n", string(list))
+
+	info, err := os.ReadFile(filepath.Join(out, "example.com", "a", "@v", "v1.0.0.info"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"Version":"v1.0.0"}`, string(info))
+
+	hash, err := resolve.NARHashZip(filepath.Join(out, "example.com", "!upper", "@v", "v0.2.0.zip"),
+		module.Version{Path: "example.com/Upper", Version: "v0.2.0"})
+	require.NoError(t, err)
+	assert.Equal(t, hashB, hash)
+}
+
+func TestWriteMirrorsRequiredGoModFiles(t *testing.T) {
+	cache := t.TempDir()
+	hash := publish(t, cache, "example.com/a", "v1.0.0", "example.com/dep v1.1.0", "example.com/gone v0.1.0")
+	publish(t, cache, "example.com/dep", "v1.1.0", "example.com/a v1.0.0", "example.com/indirect v0.3.0")
+	publish(t, cache, "example.com/indirect", "v0.3.0")
+
+	out := t.TempDir()
+	n, err := mirror.New(out, fileProxy(t, cache)).Write(context.Background(), []mod.ModuleConfig{
+		{Path: "example.com/a", Version: "v1.0.0", Hash: hash},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	for _, path := range []string{"dep/@v/v1.1.0", "indirect/@v/v0.3.0"} {
+		base := filepath.Join(out, "example.com", filepath.FromSlash(path))
+		assert.FileExists(t, base+".mod")
+		assert.FileExists(t, base+".info")
+		assert.NoFileExists(t, base+".zip")
+	}
+	assert.NoDirExists(t, filepath.Join(out, "example.com", "gone"))
+
+	list, err := os.ReadFile(filepath.Join(out, "example.com", "dep", "@v", "list"))
+	require.NoError(t, err)
+	assert.Equal(t, "v1.1.0\n", string(list))
+}
+
+func TestWriteMirrorsModulesWithoutSource(t *testing.T) {
+	proxyDir := t.TempDir()
+	publish(t, proxyDir, "example.com/unused", "v1.0.0")
+
+	out := t.TempDir()
+	n, err := mirror.New(out, fileProxy(t, proxyDir)).Write(context.Background(), []mod.ModuleConfig{
+		{Path: "example.com/unused", Version: "v1.0.0"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	dir := filepath.Join(out, "example.com", "unused", "@v")
+	assert.FileExists(t, filepath.Join(dir, "v1.0.0.mod"))
+	assert.FileExists(t, filepath.Join(dir, "v1.0.0.info"))
+	assert.NoFileExists(t, filepath.Join(dir, "v1.0.0.zip"))
+}
+
+func TestWriteMergesVersionList(t *testing.T) {
+	cache := t.TempDir()
+	hash := publish(t, cache, "example.com/a", "v1.0.0")
+
+	out := t.TempDir()
+	listDir := filepath.Join(out, "example.com", "a", "@v")
+	require.NoError(t, os.MkdirAll(listDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(listDir, "list"), []byte("v1.1.0\nv0.9.0\n"), 0o644))
+
+	_, err := mirror.New(out, fileProxy(t, cache)).Write(context.Background(), []mod.ModuleConfig{
+		{Path: "example.com/a", Version: "v1.0.0", Hash: hash},
+	})
+	require.NoError(t, err)
+
+	list, err := os.ReadFile(filepath.Join(listDir, "list"))
+	require.NoError(t, err)
+	assert.Equal(t, "v0.9.0\nv1.0.0\nv1.1.0\n", string(list))
+}
+
+func TestWriteRejectsHashMismatch(t *testing.T) {
+	cache := t.TempDir()
+	publish(t, cache, "example.com/a", "v1.0.0")
+
+	out := t.TempDir()
+	_, err := mirror.New(out, fileProxy(t, cache)).Write(context.Background(), []mod.ModuleConfig{
+		{Path: "example.com/a", Version: "v1.0.0", Hash: "sha256-AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="},
+	})
+	require.ErrorContains(t, err, "but the manifest records sha256-AAAA")
+	assert.NoFileExists(t, filepath.Join(out, "example.com", "a", "@v", "v1.0.0.zip"))
+}
+
+func TestWriteReportsMissingModules(t *testing.T) {
+	_, err := mirror.New(t.TempDir(), fileProxy(t, t.TempDir())).Write(context.Background(), []mod.ModuleConfig{
+		{Path: "example.com/a", Version: "v1.0.0", Hash: "sha256-a"},
+	})
+	require.ErrorIs(t, err, goproxy.ErrNotFound)
+}
@@ -15,13 +15,22 @@ const GoModFilename = "go.mod"
 // GoModFile is a parsed go.mod file. All fields are extracted at parse
 // time. No methods shell out to external processes.
 type GoModFile struct {
-	Dir          string
+	Dir string
+
+	// Filename is the name of the parsed file within Dir. It is empty or
+	// go.mod, unless the module is read from an alternate modfile, such as
+	// tools.mod, which the go command selects with -modfile.
+	Filename string
+
 	ModulePath   string
 	GoVersion    string
+	Toolchain    string
+	Godebug      map[string]string
 	Requires     map[string]string
 	Tools        []string
 	Replacements map[string]Replacement
 	Excludes     map[string][]string
+	Ignores      []string
 }
 
 func ParseGoModFile(path string) (*GoModFile, error) {
@@ -44,6 +53,11 @@ func ParseGoModFile(path string) (*GoModFile, error) {
 		goVersion = mf.Go.Version
 	}
 
+	var toolchain string
+	if mf.Toolchain != nil {
+		toolchain = mf.Toolchain.Name
+	}
+
 	requires := make(map[string]string, len(mf.Require))
 	for _, req := range mf.Require {
 		requires[req.Mod.Path] = req.Mod.Version
@@ -59,15 +73,65 @@ func ParseGoModFile(path string) (*GoModFile, error) {
 
 	return &GoModFile{
 		Dir:          filepath.Dir(path),
+		Filename:     filepath.Base(path),
 		ModulePath:   mf.Module.Mod.Path,
 		GoVersion:    goVersion,
+		Toolchain:    toolchain,
+		Godebug:      parseGodebugs(mf.Godebug),
 		Requires:     requires,
 		Tools:        tools,
 		Replacements: replacements,
 		Excludes:     excludes,
+		Ignores:      parseIgnores(mf.Ignore),
 	}, nil
 }
 
+// IsAlternateModFile reports whether name is the file name of an alternate
+// modfile, one ending in .mod other than go.mod.
+func IsAlternateModFile(name string) bool {
+	return name != GoModFilename && strings.HasSuffix(name, ".mod") && name != ".mod"
+}
+
+// IsAlternate reports whether the module is read from an alternate modfile.
+func (f *GoModFile) IsAlternate() bool {
+	return IsAlternateModFile(f.Filename)
+}
+
+// ModFile returns the path of the parsed modfile.
+func (f *GoModFile) ModFile() string {
+	if f.IsAlternate() {
+		return filepath.Join(f.Dir, f.Filename)
+	}
+	return filepath.Join(f.Dir, GoModFilename)
+}
+
+// SumFile returns the path of the checksum file paired with the modfile. As
+// with the go command, tools.mod is paired with tools.sum.
+func (f *GoModFile) SumFile() string {
+	return strings.TrimSuffix(f.ModFile(), ".mod") + ".sum"
+}
+
+// ModFileFlags returns the flags that select the modfile for go commands run
+// from Dir, which are only needed for an alternate modfile.
+func (f *GoModFile) ModFileFlags() []string {
+	if f.IsAlternate() {
+		return []string{"-modfile=" + f.Filename}
+	}
+	return nil
+}
+
+// MainModuleConfig returns the directives of go.mod recorded in the manifest,
+// or nil when it declares neither a toolchain nor any godebug settings.
+func (f *GoModFile) MainModuleConfig() *MainModuleConfig {
+	if f.Toolchain == "" && len(f.Godebug) == 0 {
+		return nil
+	}
+	return &MainModuleConfig{
+		Toolchain: f.Toolchain,
+		Godebug:   f.Godebug,
+	}
+}
+
 func (f *GoModFile) HasDependencies() bool {
 	return len(f.Requires) > 0
 }
@@ -125,6 +189,28 @@ func parseReplacements(repls []*modfile.Replace) map[string]Replacement {
 	return replacements
 }
 
+// parseGodebugs returns the godebug directives of a go.mod or go.work as
+// key/value settings, or nil when there are none.
+func parseGodebugs(godebugs []*modfile.Godebug) map[string]string {
+	if len(godebugs) == 0 {
+		return nil
+	}
+
+	settings := make(map[string]string, len(godebugs))
+	for _, g := range godebugs {
+		settings[g.Key] = g.Value
+	}
+	return settings
+}
+
+func parseIgnores(ignores []*modfile.Ignore) []string {
+	var patterns []string
+	for _, i := range ignores {
+		patterns = append(patterns, i.Path)
+	}
+	return patterns
+}
+
 func parseExcludes(excls []*modfile.Exclude) map[string][]string {
 	excludes := make(map[string][]string, len(excls))
 	for _, exc := range excls {
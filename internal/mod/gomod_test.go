@@ -43,6 +43,7 @@ require (
 	assert.Equal(t, dir, goMod.Dir)
 	assert.Equal(t, "github.com/purpleclay/example/with-deps", goMod.ModulePath)
 	assert.Equal(t, "1.25.4", goMod.GoVersion)
+	assert.Empty(t, goMod.Toolchain)
 	assert.True(t, goMod.HasDependencies())
 	assert.False(t, goMod.HasTools())
 
@@ -54,6 +55,108 @@ require (
 	assert.Equal(t, "v0.25.0", reqs["golang.org/x/sys"])
 }
 
+func TestParseGoModFileToolchain(t *testing.T) {
+	content := `
+module github.com/purpleclay/example/toolchain
+
+go 1.25.0
+
+toolchain go1.25.4
+`
+	dir := t.TempDir()
+	path := writeFile(t, dir, goModFile, content)
+
+	goMod, err := mod.ParseGoModFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "1.25.0", goMod.GoVersion)
+	assert.Equal(t, "go1.25.4", goMod.Toolchain)
+	assert.Equal(t, &mod.MainModuleConfig{Toolchain: "go1.25.4"}, goMod.MainModuleConfig())
+}
+
+func TestParseGoModFileGodebug(t *testing.T) {
+	content := `
+module github.com/purpleclay/example/godebug
+
+go 1.25.0
+
+godebug default=go1.21
+
+godebug (
+	panicnil=1
+	asynctimerchan=0
+)
+`
+	dir := t.TempDir()
+	path := writeFile(t, dir, goModFile, content)
+
+	goMod, err := mod.ParseGoModFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"default": "go1.21", "panicnil": "1", "asynctimerchan": "0"}, goMod.Godebug)
+	assert.Equal(t, goMod.Godebug, goMod.MainModuleConfig().Godebug)
+}
+
+func TestParseGoModFileIgnores(t *testing.T) {
+	content := `
+module github.com/purpleclay/example/ignore
+
+go 1.25.0
+
+ignore ./web
+
+ignore (
+	node_modules
+	./fixtures
+)
+`
+	dir := t.TempDir()
+	path := writeFile(t, dir, goModFile, content)
+
+	goMod, err := mod.ParseGoModFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"./web", "node_modules", "./fixtures"}, goMod.Ignores)
+}
+
+func TestParseGoModFileWithoutDirectivesHasNoMainModuleConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, goModFile, "module github.com/purpleclay/example\n\ngo 1.25.0\n")
+
+	goMod, err := mod.ParseGoModFile(path)
+	require.NoError(t, err)
+	assert.Nil(t, goMod.MainModuleConfig())
+}
+
+func TestParseGoModFileAlternateModFile(t *testing.T) {
+	content := `
+module github.com/purpleclay/example/tools
+
+go 1.25.4
+
+tool golang.org/x/tools/cmd/stringer
+`
+	dir := t.TempDir()
+	path := writeFile(t, dir, "tools.mod", content)
+
+	goMod, err := mod.ParseGoModFile(path)
+	require.NoError(t, err)
+
+	assert.True(t, goMod.IsAlternate())
+	assert.Equal(t, path, goMod.ModFile())
+	assert.Equal(t, filepath.Join(dir, "tools.sum"), goMod.SumFile())
+	assert.Equal(t, []string{"-modfile=tools.mod"}, goMod.ModFileFlags())
+}
+
+func TestParseGoModFileDefaultModFile(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, goModFile, "module github.com/purpleclay/example\n")
+
+	goMod, err := mod.ParseGoModFile(path)
+	require.NoError(t, err)
+
+	assert.False(t, goMod.IsAlternate())
+	assert.Equal(t, filepath.Join(dir, "go.sum"), goMod.SumFile())
+	assert.Empty(t, goMod.ModFileFlags())
+}
+
 func TestParseGoModFileReturnsErrorForMissingModuleDirective(t *testing.T) {
 	dir := t.TempDir()
 	path := writeFile(t, dir, goModFile, "go 1.25.4\n")
@@ -21,6 +21,7 @@ type WorkspaceMember struct {
 	Tools        []string
 	Replacements map[string]Replacement
 	Excludes     map[string][]string
+	Ignores      []string
 }
 
 // GoWorkFile is a parsed go.work file. All fields are extracted at
@@ -29,6 +30,7 @@ type GoWorkFile struct {
 	Dir          string
 	GoVersion    string
 	Toolchain    string
+	Godebug      map[string]string
 	Modules      []string
 	Replacements map[string]Replacement
 }
@@ -63,6 +65,7 @@ func ParseGoWorkFile(path string) (*GoWorkFile, error) {
 		Dir:          filepath.Dir(path),
 		GoVersion:    goVersion,
 		Toolchain:    toolchain,
+		Godebug:      parseGodebugs(wf.Godebug),
 		Modules:      modules,
 		Replacements: parseReplacements(wf.Replace),
 	}, nil
@@ -82,6 +85,7 @@ func NewGoWorkFileFromManifest(dir string, config *WorkspaceConfig) (*GoWorkFile
 		Dir:       dir,
 		GoVersion: config.Go,
 		Toolchain: config.Toolchain,
+		Godebug:   config.Godebug,
 		Modules:   modules,
 	}, nil
 }
@@ -131,6 +135,7 @@ func (w *GoWorkFile) WorkspaceConfig() *WorkspaceConfig {
 	return &WorkspaceConfig{
 		Go:        w.GoVersion,
 		Toolchain: w.Toolchain,
+		Godebug:   w.Godebug,
 		Modules:   modules,
 	}
 }
@@ -177,6 +182,7 @@ func (w *GoWorkFile) ParseMembers() ([]WorkspaceMember, error) {
 			Tools:        tools,
 			Replacements: replacements,
 			Excludes:     excludes,
+			Ignores:      parseIgnores(mf.Ignore),
 		})
 	}
 
@@ -17,6 +17,11 @@ go 1.25.4
 
 toolchain go1.25.4
 
+godebug (
+	default=go1.21
+	panicnil=1
+)
+
 use (
 	./cli
 	./core
@@ -49,6 +54,7 @@ go 1.25.4
 	require.NotNil(t, cfg)
 	assert.Equal(t, "1.25.4", cfg.Go)
 	assert.Equal(t, "go1.25.4", cfg.Toolchain)
+	assert.Equal(t, map[string]string{"default": "go1.21", "panicnil": "1"}, cfg.Godebug)
 	assert.Equal(t, []string{"./cli", "./core"}, cfg.Modules)
 }
 
@@ -156,6 +162,7 @@ func TestNewGoWorkFileFromManifest(t *testing.T) {
 	cfg := &mod.WorkspaceConfig{
 		Go:        "1.25.4",
 		Toolchain: "go1.25.4",
+		Godebug:   map[string]string{"panicnil": "1"},
 		Modules:   []string{"./cli", "./core"},
 	}
 
@@ -0,0 +1,40 @@
+package mod
+
+import (
+	"path"
+	"strings"
+)
+
+// IsIgnored reports whether dir, a slash-separated path relative to a module
+// root, is excluded by the module's ignore directives. As with the go
+// command, a pattern such as "./web" ignores that directory at the module
+// root, while "node_modules" ignores any directory with that path at any
+// depth. Either ignores the whole subtree.
+func IsIgnored(patterns []string, dir string) bool {
+	if dir == "" || dir == "." {
+		return false
+	}
+
+	dir = slashed(dir)
+	for _, pattern := range patterns {
+		rooted, isRooted := strings.CutPrefix(pattern, "./")
+		if isRooted {
+			if strings.HasPrefix(dir, slashed(rooted)) {
+				return true
+			}
+		} else if strings.Contains(dir, slashed(pattern)) {
+			return true
+		}
+	}
+	return false
+}
+
+// slashed returns a cleaned path with a leading and trailing slash, so
+// paths are only matched on whole elements.
+func slashed(p string) string {
+	p = path.Clean("/" + p)
+	if p == "/" {
+		return p
+	}
+	return p + "/"
+}
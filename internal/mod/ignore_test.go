@@ -0,0 +1,33 @@
+package mod_test
+
+import (
+	"testing"
+
+	"github.com/purpleclay/go-overlay/internal/mod"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsIgnored(t *testing.T) {
+	patterns := []string{"./web", "node_modules", "fixtures/golden"}
+
+	tests := []struct {
+		dir  string
+		want bool
+	}{
+		{dir: ".", want: false},
+		{dir: "web", want: true},
+		{dir: "web/src", want: true},
+		{dir: "api/web", want: false},
+		{dir: "webapp", want: false},
+		{dir: "node_modules", want: true},
+		{dir: "ui/node_modules/pkg", want: true},
+		{dir: "ui/node_modules_old", want: false},
+		{dir: "internal/fixtures/golden/case", want: true},
+		{dir: "internal/fixtures", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dir, func(t *testing.T) {
+			assert.Equal(t, tt.want, mod.IsIgnored(patterns, tt.dir))
+		})
+	}
+}
@@ -3,26 +3,77 @@ package mod
 // ModuleConfig represents a single Go module dependency, both as the result
 // of dependency resolution and as a row in the generated manifest.
 type ModuleConfig struct {
-	Path         string   `toml:"-"`
-	Version      string   `toml:"version"`
-	Hash         string   `toml:"hash,omitempty"`
-	GoVersion    string   `toml:"go,omitempty"`
-	Packages     []string `toml:"packages,omitempty"`
-	ReplacedPath string   `toml:"replaced,omitempty"`
-	Local        string   `toml:"local,omitempty"`
+	Path         string   `toml:"-" json:"-"`
+	Version      string   `toml:"version" json:"version"`
+	Hash         string   `toml:"hash,omitempty" json:"hash,omitempty"`
+	GoVersion    string   `toml:"go,omitempty" json:"go,omitempty"`
+	Packages     []string `toml:"packages,omitempty" json:"packages,omitempty"`
+	Scope        []string `toml:"scope,omitempty" json:"scope,omitempty"`
+	TestPackages []string `toml:"test_packages,omitempty" json:"test_packages,omitempty"`
+	ToolPackages []string `toml:"tool_packages,omitempty" json:"tool_packages,omitempty"`
+	Cgo          bool     `toml:"cgo,omitempty" json:"cgo,omitempty"`
+	Embed        bool     `toml:"embed,omitempty" json:"embed,omitempty"`
+	ReplacedPath string   `toml:"replaced,omitempty" json:"replaced,omitempty"`
+	Local        string   `toml:"local,omitempty" json:"local,omitempty"`
+	URL          string   `toml:"url,omitempty" json:"url,omitempty"`
+	ZipHash      string   `toml:"zip_hash,omitempty" json:"zip_hash,omitempty"`
+	Private      string   `toml:"private,omitempty" json:"private,omitempty"`
+}
+
+// Scopes a module or package can be needed in, besides the build itself. A
+// module without a recorded scope is needed by the build.
+const (
+	ScopeTest = "test"
+	ScopeTool = "tool"
+)
+
+// Features a module's packages can use that affect how it is built. A
+// manifest lists the features it records, so the absence of one on a module
+// is only meaningful when the manifest lists it.
+const (
+	FeatureCgo   = "cgo"
+	FeatureEmbed = "embed"
+)
+
+// Features lists every feature recorded against modules.
+var Features = []string{FeatureCgo, FeatureEmbed}
+
+// IsRemote reports whether the source of a module is fetched from a module
+// proxy, rather than being a local replacement or workspace member.
+func (m ModuleConfig) IsRemote() bool {
+	return m.Local == "" && m.Hash != ""
+}
+
+// FetchPath returns the module path the source of a module is fetched from,
+// which for a remote replacement is the replacement path.
+func (m ModuleConfig) FetchPath() string {
+	if m.ReplacedPath != "" {
+		return m.ReplacedPath
+	}
+	return m.Path
 }
 
 // WorkspaceConfig holds Go workspace metadata recorded in the manifest. It is
 // also used to reconstruct a GoWorkFile when go.work is not committed.
 type WorkspaceConfig struct {
-	Go        string   `toml:"go"`
-	Toolchain string   `toml:"toolchain,omitempty"`
-	Modules   []string `toml:"modules"`
+	Go        string            `toml:"go" json:"go"`
+	Toolchain string            `toml:"toolchain,omitempty" json:"toolchain,omitempty"`
+	Godebug   map[string]string `toml:"godebug,omitempty" json:"godebug,omitempty"`
+	Modules   []string          `toml:"modules" json:"modules"`
+}
+
+// MainModuleConfig holds the toolchain and godebug directives of a single
+// module's go.mod, recorded in the manifest so a change to either is drift.
+// The go command reads both to select a toolchain and the GODEBUG defaults
+// of the binaries it builds.
+type MainModuleConfig struct {
+	Toolchain string            `toml:"toolchain,omitempty" json:"toolchain,omitempty"`
+	Godebug   map[string]string `toml:"godebug,omitempty" json:"godebug,omitempty"`
 }
 
 // ToolEntry records the resolved version of a single Go tool directive.
 type ToolEntry struct {
-	Version string `toml:"version"`
+	Version string `toml:"version" json:"version"`
 }
 
 // ToolConfig records Go tool directive packages in the manifest, keyed by
@@ -0,0 +1,57 @@
+package mod
+
+import (
+	"os"
+	"slices"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+// PrivatePatterns are the glob patterns of module path prefixes the go
+// command fetches directly from their origin rather than through a proxy,
+// so they usually need credentials to fetch.
+type PrivatePatterns []string
+
+// PrivatePatternsFromEnv returns the patterns of GOPRIVATE and GONOPROXY,
+// which defaults to GOPRIVATE when unset.
+func PrivatePatternsFromEnv() PrivatePatterns {
+	return PrivatePatternsFrom(os.LookupEnv)
+}
+
+// PrivatePatternsFrom returns the patterns of GOPRIVATE and GONOPROXY, as
+// reported by lookup, with GONOPROXY defaulting to GOPRIVATE when unset.
+func PrivatePatternsFrom(lookup func(key string) (string, bool)) PrivatePatterns {
+	goPrivate, _ := lookup("GOPRIVATE")
+	noProxy, ok := lookup("GONOPROXY")
+	if !ok {
+		noProxy = goPrivate
+	}
+	return ParsePrivatePatterns(goPrivate, noProxy)
+}
+
+// ParsePrivatePatterns splits comma-separated pattern lists, such as the
+// values of GOPRIVATE and GONOPROXY, dropping empty and repeated patterns.
+func ParsePrivatePatterns(lists ...string) PrivatePatterns {
+	var patterns PrivatePatterns
+	for _, list := range lists {
+		for pattern := range strings.SplitSeq(list, ",") {
+			pattern = strings.TrimSpace(pattern)
+			if pattern != "" && !slices.Contains(patterns, pattern) {
+				patterns = append(patterns, pattern)
+			}
+		}
+	}
+	return patterns
+}
+
+// Match returns the first pattern matching a prefix of the module path, as
+// the go command matches GOPRIVATE.
+func (p PrivatePatterns) Match(path string) (string, bool) {
+	for _, pattern := range p {
+		if module.MatchPrefixPatterns(pattern, path) {
+			return pattern, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,57 @@
+package mod_test
+
+import (
+	"testing"
+
+	"github.com/purpleclay/go-overlay/internal/mod"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePrivatePatterns(t *testing.T) {
+	patterns := mod.ParsePrivatePatterns("github.com/acme/*, example.com/internal", "example.com/internal,,corp.dev")
+
+	assert.Equal(t, mod.PrivatePatterns{"github.com/acme/*", "example.com/internal", "corp.dev"}, patterns)
+}
+
+func TestPrivatePatternsMatch(t *testing.T) {
+	patterns := mod.ParsePrivatePatterns("github.com/acme/*,corp.dev")
+
+	tests := []struct {
+		path    string
+		pattern string
+		private bool
+	}{
+		{path: "github.com/acme/widgets", pattern: "github.com/acme/*", private: true},
+		{path: "github.com/acme/widgets/v2", pattern: "github.com/acme/*", private: true},
+		{path: "corp.dev/platform/auth", pattern: "corp.dev", private: true},
+		{path: "github.com/acme", private: false},
+		{path: "github.com/fatih/color", private: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			pattern, private := patterns.Match(tt.path)
+			assert.Equal(t, tt.private, private)
+			assert.Equal(t, tt.pattern, pattern)
+		})
+	}
+}
+
+func TestPrivatePatternsFromDefaultsNoProxyToPrivate(t *testing.T) {
+	env := map[string]string{"GOPRIVATE": "github.com/acme/*"}
+	lookup := func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+
+	assert.Equal(t, mod.PrivatePatterns{"github.com/acme/*"}, mod.PrivatePatternsFrom(lookup))
+}
+
+func TestPrivatePatternsFromIncludesNoProxy(t *testing.T) {
+	env := map[string]string{"GOPRIVATE": "github.com/acme/*", "GONOPROXY": "corp.dev"}
+	lookup := func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+
+	assert.Equal(t, mod.PrivatePatterns{"github.com/acme/*", "corp.dev"}, mod.PrivatePatternsFrom(lookup))
+}
@@ -0,0 +1,220 @@
+package nixcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/nix-community/go-nix/pkg/narinfo"
+	"github.com/nix-community/go-nix/pkg/nixbase32"
+	"github.com/nix-community/go-nix/pkg/nixhash"
+	"github.com/nix-community/go-nix/pkg/storepath"
+	"github.com/purpleclay/conker/pool"
+	"github.com/purpleclay/go-overlay/internal/goproxy"
+	"github.com/purpleclay/go-overlay/internal/mod"
+	"github.com/purpleclay/go-overlay/internal/resolve"
+	"golang.org/x/mod/module"
+)
+
+// cacheInfo is the nix-cache-info file at the root of a binary cache.
+const cacheInfo = "StoreDir: " + storepath.StoreDir + "\nWantMassQuery: 1\n"
+
+// Source serves module zips using the GOPROXY protocol. *goproxy.Client
+// satisfies it, for both module caches and remote proxies.
+type Source interface {
+	Zip(ctx context.Context, path, version string) (io.ReadCloser, error)
+}
+
+// Exporter writes module sources as a file-based Nix binary cache, which can
+// be used as a substituter with file://DIR. Each module is written as an
+// uncompressed NAR and a narinfo keyed by the store path of its fetchGoModule
+// derivation, so Nix substitutes the module instead of building it.
+//
+// The narinfo files are unsigned but record the content address of each
+// path, which Nix verifies in place of a signature.
+type Exporter struct {
+	dir     string
+	sources []Source
+}
+
+// NewExporter creates an Exporter writing to dir. Each module is taken from
+// the first source that has it.
+func NewExporter(dir string, sources ...Source) *Exporter {
+	return &Exporter{dir: dir, sources: sources}
+}
+
+// Write exports every remote module in modules, skipping local replacements
+// and workspace members, and returns how many were exported. Modules already
+// in the cache are left untouched.
+func (e *Exporter) Write(ctx context.Context, modules []mod.ModuleConfig) (int, error) {
+	if err := os.MkdirAll(filepath.Join(e.dir, "nar"), 0o755); err != nil {
+		return 0, fmt.Errorf("failed to create binary cache directory: %w", err)
+	}
+	if err := writeFile(filepath.Join(e.dir, "nix-cache-info"), []byte(cacheInfo)); err != nil {
+		return 0, err
+	}
+
+	remote := make(map[string]mod.ModuleConfig)
+	for _, cfg := range modules {
+		if !cfg.IsRemote() {
+			continue
+		}
+
+		sp, err := ModuleStorePath(cfg)
+		if err != nil {
+			return 0, fmt.Errorf("%s@%s: %w", cfg.Path, cfg.Version, err)
+		}
+		remote[sp.String()] = cfg
+	}
+
+	p := pool.New().WithMaxGoroutines(8).WithContext(ctx)
+	for _, cfg := range remote {
+		p.Go(func(ctx context.Context) error {
+			return e.export(ctx, cfg)
+		})
+	}
+	if err := p.Wait(); err != nil {
+		return 0, err
+	}
+	return len(remote), nil
+}
+
+func (e *Exporter) export(ctx context.Context, cfg mod.ModuleConfig) error {
+	sp, err := ModuleStorePath(cfg)
+	if err != nil {
+		return err
+	}
+
+	narinfoPath := filepath.Join(e.dir, nixbase32.EncodeToString(sp.Digest)+".narinfo")
+	if _, err := os.Stat(narinfoPath); err == nil {
+		return nil
+	}
+
+	m := module.Version{Path: cfg.FetchPath(), Version: cfg.Version}
+	zipFile, err := e.fetchZip(ctx, m)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(zipFile)
+
+	nar, err := os.CreateTemp(filepath.Join(e.dir, "nar"), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(nar.Name())
+	defer nar.Close()
+
+	h := sha256.New()
+	cw := &countingWriter{w: io.MultiWriter(nar, h)}
+	if err := resolve.WriteZipNAR(cw, zipFile, m); err != nil {
+		return err
+	}
+	if err := nar.Close(); err != nil {
+		return err
+	}
+
+	narHash := nixhash.MustNewHashWithEncoding(nixhash.SHA256, h.Sum(nil), nixhash.NixBase32, true)
+	if sri := narHash.Format(nixhash.SRI, true); sri != cfg.Hash {
+		return fmt.Errorf("%s@%s: module hashes to %s, but the manifest records %s", m.Path, m.Version, sri, cfg.Hash)
+	}
+
+	narFile := "nar/" + nixbase32.EncodeToString(narHash.Digest()) + ".nar"
+	if err := rename(nar.Name(), filepath.Join(e.dir, filepath.FromSlash(narFile))); err != nil {
+		return err
+	}
+
+	info := narinfo.NarInfo{
+		StorePath:   sp.Absolute(),
+		URL:         narFile,
+		Compression: "none",
+		FileHash:    narHash,
+		FileSize:    cw.n,
+		NarHash:     narHash,
+		NarSize:     cw.n,
+		CA:          "fixed:r:" + narHash.String(),
+	}
+	return writeFile(narinfoPath, []byte(info.String()))
+}
+
+// fetchZip copies the zip of a module from the first source that has it into
+// a temporary file.
+func (e *Exporter) fetchZip(ctx context.Context, m module.Version) (string, error) {
+	var lastErr error
+	for _, src := range e.sources {
+		rc, err := src.Zip(ctx, m.Path, m.Version)
+		if errors.Is(err, goproxy.ErrNotFound) {
+			lastErr = err
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+
+		tmp, err := copyToTemp(e.dir, rc)
+		rc.Close()
+		if err != nil {
+			return "", fmt.Errorf("failed to download %s@%s: %w", m.Path, m.Version, err)
+		}
+		return tmp, nil
+	}
+	return "", lastErr
+}
+
+type countingWriter struct {
+	w io.Writer
+	n uint64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += uint64(n)
+	return n, err
+}
+
+func copyToTemp(dir string, r io.Reader) (string, error) {
+	f, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, r); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), f.Close()
+}
+
+// writeFile writes data to path through a temporary file, so an interrupted
+// run never leaves a partial narinfo for Nix to substitute from.
+func writeFile(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(data); err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return rename(f.Name(), path)
+}
+
+// rename moves a temporary file into place, making it readable by anyone
+// serving the cache.
+func rename(tmp, path string) error {
+	if err := os.Chmod(tmp, 0o644); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,124 @@
+package nixcache_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nix-community/go-nix/pkg/narinfo"
+	"github.com/purpleclay/go-overlay/internal/goproxy"
+	"github.com/purpleclay/go-overlay/internal/mod"
+	"github.com/purpleclay/go-overlay/internal/nixcache"
+	"github.com/purpleclay/go-overlay/internal/resolve"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/module"
+	modzip "golang.org/x/mod/zip"
+)
+
+// publish writes a module to a file-based proxy directory, returning its
+// NAR hash.
+func publish(t *testing.T, proxyDir, path, version string) string {
+	t.Helper()
+	src := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(src, "go.mod"), []byte("module "+path+"\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "sub"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "sub", "lib.go"), []byte("package sub\n"), 0o644))
+
+	m := module.Version{Path: path, Version: version}
+	escPath, err := module.EscapePath(path)
+	require.NoError(t, err)
+	dir := filepath.Join(proxyDir, filepath.FromSlash(escPath), "@v")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+
+	zipFile := filepath.Join(dir, version+".zip")
+	f, err := os.Create(zipFile)
+	require.NoError(t, err)
+	require.NoError(t, modzip.CreateFromDir(f, m, src))
+	require.NoError(t, f.Close())
+
+	hash, err := resolve.NARHashZip(zipFile, m)
+	require.NoError(t, err)
+	return hash
+}
+
+func fileProxy(t *testing.T, dir string) *goproxy.Client {
+	t.Helper()
+	client, err := goproxy.New("file://" + dir)
+	require.NoError(t, err)
+	return client
+}
+
+func TestExporterWrite(t *testing.T) {
+	cache := t.TempDir()
+	hash := publish(t, cache, "example.com/a", "v1.0.0")
+	cfg := mod.ModuleConfig{Path: "example.com/a", Version: "v1.0.0", Hash: hash}
+
+	out := t.TempDir()
+	n, err := nixcache.NewExporter(out, fileProxy(t, cache)).Write(context.Background(), []mod.ModuleConfig{
+		cfg,
+		{Path: "example.com/local", Version: "v0.0.0", Local: "./local"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	cacheInfo, err := os.ReadFile(filepath.Join(out, "nix-cache-info"))
+	require.NoError(t, err)
+	assert.Contains(t, string(cacheInfo), "StoreDir: /nix/store\n")
+
+	sp, err := nixcache.ModuleStorePath(cfg)
+	require.NoError(t, err)
+	hashPart, _, _ := strings.Cut(sp.String(), "-")
+
+	f, err := os.Open(filepath.Join(out, hashPart+".narinfo"))
+	require.NoError(t, err)
+	defer f.Close()
+	info, err := narinfo.Parse(f)
+	require.NoError(t, err)
+	require.NoError(t, info.Check())
+
+	assert.Equal(t, sp.Absolute(), info.StorePath)
+	assert.Equal(t, "none", info.Compression)
+	assert.Equal(t, "fixed:r:"+info.NarHash.String(), info.CA)
+	assert.Empty(t, info.References)
+
+	nar, err := os.ReadFile(filepath.Join(out, filepath.FromSlash(info.URL)))
+	require.NoError(t, err)
+	sum := sha256.Sum256(nar)
+	assert.Equal(t, hash, "sha256-"+base64.StdEncoding.EncodeToString(sum[:]))
+	assert.Equal(t, uint64(len(nar)), info.NarSize)
+}
+
+func TestExporterWriteSkipsExportedModules(t *testing.T) {
+	cache := t.TempDir()
+	hash := publish(t, cache, "example.com/a", "v1.0.0")
+	modules := []mod.ModuleConfig{{Path: "example.com/a", Version: "v1.0.0", Hash: hash}}
+
+	out := t.TempDir()
+	_, err := nixcache.NewExporter(out, fileProxy(t, cache)).Write(context.Background(), modules)
+	require.NoError(t, err)
+
+	// The module is no longer available from any source, so a second run
+	// only succeeds if it is skipped.
+	_, err = nixcache.NewExporter(out, fileProxy(t, t.TempDir())).Write(context.Background(), modules)
+	require.NoError(t, err)
+}
+
+func TestExporterWriteRejectsHashMismatch(t *testing.T) {
+	cache := t.TempDir()
+	publish(t, cache, "example.com/a", "v1.0.0")
+
+	out := t.TempDir()
+	_, err := nixcache.NewExporter(out, fileProxy(t, cache)).Write(context.Background(), []mod.ModuleConfig{
+		{Path: "example.com/a", Version: "v1.0.0", Hash: "sha256-CIE8vumQPGK+TFAncmpBijANpFALLTadOvkob0gVzro="},
+	})
+	require.ErrorContains(t, err, "but the manifest records sha256-CIE8")
+
+	entries, err := os.ReadDir(filepath.Join(out, "nar"))
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -0,0 +1,166 @@
+package nixcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nix-community/go-nix/pkg/nixbase32"
+	"github.com/purpleclay/conker/pool"
+	"github.com/purpleclay/go-overlay/internal/mod"
+	"golang.org/x/mod/module"
+)
+
+// DefaultCache is the binary cache queried when none is given.
+const DefaultCache = "https://cache.nixos.org"
+
+// ModuleStatus reports whether the fetchGoModule derivation of a module can
+// be substituted from a binary cache, or will be fetched from the network.
+type ModuleStatus struct {
+	Path      string `json:"path"`
+	Version   string `json:"version"`
+	StorePath string `json:"store_path"`
+
+	// Cache is the first binary cache holding the store path, or empty when
+	// no cache holds it.
+	Cache string `json:"cache,omitempty"`
+}
+
+// Substitutable reports whether any binary cache holds the module.
+func (s ModuleStatus) Substitutable() bool {
+	return s.Cache != ""
+}
+
+// Checker queries binary caches for the store paths of modules. Caches are
+// queried in order, using the narinfo lookup Nix performs before
+// substituting a path. Both HTTP(S) and file:// caches are supported.
+type Checker struct {
+	caches []string
+	http   *http.Client
+}
+
+// CheckerOption configures a Checker.
+type CheckerOption func(*Checker)
+
+// WithHTTPClient sets the client used to query HTTP(S) caches.
+func WithHTTPClient(client *http.Client) CheckerOption {
+	return func(c *Checker) {
+		c.http = client
+	}
+}
+
+// NewChecker creates a Checker querying caches, or DefaultCache when none
+// are given.
+func NewChecker(caches []string, opts ...CheckerOption) *Checker {
+	if len(caches) == 0 {
+		caches = []string{DefaultCache}
+	}
+
+	c := &Checker{http: &http.Client{Timeout: 30 * time.Second}}
+	for _, cache := range caches {
+		c.caches = append(c.caches, strings.TrimSuffix(cache, "/"))
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Status returns the cache status of every remote module in modules,
+// skipping local replacements and workspace members, sorted by module path.
+// A module recorded by several manifests is queried once.
+func (c *Checker) Status(ctx context.Context, modules []mod.ModuleConfig) ([]ModuleStatus, error) {
+	remote := make(map[module.Version]mod.ModuleConfig)
+	for _, cfg := range modules {
+		if !cfg.IsRemote() {
+			continue
+		}
+
+		v := module.Version{Path: cfg.FetchPath(), Version: cfg.Version}
+		if seen, ok := remote[v]; ok && seen.Hash != cfg.Hash {
+			return nil, fmt.Errorf("%s@%s: manifests record different hashes %s and %s", v.Path, v.Version, seen.Hash, cfg.Hash)
+		}
+		remote[v] = cfg
+	}
+
+	p := pool.NewWithResults[ModuleStatus]().WithMaxGoroutines(16).WithContext(ctx)
+	for _, cfg := range remote {
+		p.Go(func(ctx context.Context) (ModuleStatus, error) {
+			return c.status(ctx, cfg)
+		})
+	}
+
+	statuses, err := p.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(statuses, func(a, b ModuleStatus) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return statuses, nil
+}
+
+func (c *Checker) status(ctx context.Context, cfg mod.ModuleConfig) (ModuleStatus, error) {
+	sp, err := ModuleStorePath(cfg)
+	if err != nil {
+		return ModuleStatus{}, fmt.Errorf("%s@%s: %w", cfg.Path, cfg.Version, err)
+	}
+
+	status := ModuleStatus{Path: cfg.Path, Version: cfg.Version, StorePath: sp.Absolute()}
+	narinfo := nixbase32.EncodeToString(sp.Digest) + ".narinfo"
+	for _, cache := range c.caches {
+		ok, err := c.lookup(ctx, cache, narinfo)
+		if err != nil {
+			return status, fmt.Errorf("%s@%s: %w", cfg.Path, cfg.Version, err)
+		}
+		if ok {
+			status.Cache = cache
+			break
+		}
+	}
+	return status, nil
+}
+
+// lookup reports whether a cache holds a narinfo file.
+func (c *Checker) lookup(ctx context.Context, cache, narinfo string) (bool, error) {
+	if dir, ok := strings.CutPrefix(cache, "file://"); ok {
+		_, err := os.Stat(filepath.Join(dir, narinfo))
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+
+	u, err := url.JoinPath(cache, narinfo)
+	if err != nil {
+		return false, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, u, nil)
+	if err != nil {
+		return false, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to query binary cache: %w", err)
+	}
+	resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	// Caches backed by S3 report a missing object as forbidden.
+	case http.StatusNotFound, http.StatusForbidden:
+		return false, nil
+	default:
+		return false, fmt.Errorf("failed to query binary cache: %s returned %s", u, resp.Status)
+	}
+}
@@ -0,0 +1,96 @@
+package nixcache_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/purpleclay/go-overlay/internal/mod"
+	"github.com/purpleclay/go-overlay/internal/nixcache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func narinfoName(t *testing.T, cfg mod.ModuleConfig) string {
+	t.Helper()
+	sp, err := nixcache.ModuleStorePath(cfg)
+	require.NoError(t, err)
+	hashPart, _, _ := strings.Cut(sp.String(), "-")
+	return "/" + hashPart + ".narinfo"
+}
+
+func TestCheckerStatus(t *testing.T) {
+	cached := mod.ModuleConfig{Path: "example.com/cached", Version: "v1.0.0", Hash: "sha256-CIE8vumQPGK+TFAncmpBijANpFALLTadOvkob0gVzro="}
+	missing := mod.ModuleConfig{Path: "example.com/missing", Version: "v1.0.0", Hash: "sha256-CIE8vumQPGK+TFAncmpBijANpFALLTadOvkob0gVzro="}
+
+	empty := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(empty.Close)
+
+	cachedPath := narinfoName(t, cached)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead && r.URL.Path == cachedPath {
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	t.Cleanup(srv.Close)
+
+	statuses, err := nixcache.NewChecker([]string{empty.URL, srv.URL + "/"}).Status(context.Background(), []mod.ModuleConfig{
+		missing,
+		cached,
+		{Path: "example.com/member", Version: "v0.0.0", Local: "./member"},
+	})
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+
+	assert.Equal(t, "example.com/cached", statuses[0].Path)
+	assert.True(t, statuses[0].Substitutable())
+	assert.Equal(t, srv.URL, statuses[0].Cache)
+	assert.Equal(t, "example.com/missing", statuses[1].Path)
+	assert.False(t, statuses[1].Substitutable())
+}
+
+func TestCheckerStatusQueriesSharedModulesOnce(t *testing.T) {
+	cfg := mod.ModuleConfig{Path: "example.com/shared", Version: "v1.0.0", Hash: "sha256-CIE8vumQPGK+TFAncmpBijANpFALLTadOvkob0gVzro="}
+
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+	}))
+	t.Cleanup(srv.Close)
+
+	statuses, err := nixcache.NewChecker([]string{srv.URL}).Status(context.Background(), []mod.ModuleConfig{cfg, cfg})
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestCheckerStatusWithFileCache(t *testing.T) {
+	proxyDir := t.TempDir()
+	hash := publish(t, proxyDir, "example.com/a", "v1.0.0")
+	modules := []mod.ModuleConfig{{Path: "example.com/a", Version: "v1.0.0", Hash: hash}}
+
+	out := t.TempDir()
+	_, err := nixcache.NewExporter(out, fileProxy(t, proxyDir)).Write(context.Background(), modules)
+	require.NoError(t, err)
+
+	statuses, err := nixcache.NewChecker([]string{"file://" + out}).Status(context.Background(), modules)
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	assert.True(t, statuses[0].Substitutable())
+}
+
+func TestCheckerStatusReportsCacheErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	t.Cleanup(srv.Close)
+
+	_, err := nixcache.NewChecker([]string{srv.URL}).Status(context.Background(), []mod.ModuleConfig{
+		{Path: "example.com/a", Version: "v1.0.0", Hash: "sha256-CIE8vumQPGK+TFAncmpBijANpFALLTadOvkob0gVzro="},
+	})
+	require.ErrorContains(t, err, "502 Bad Gateway")
+}
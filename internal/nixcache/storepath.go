@@ -0,0 +1,47 @@
+package nixcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/nix-community/go-nix/pkg/nixhash"
+	"github.com/nix-community/go-nix/pkg/storepath"
+	"github.com/purpleclay/go-overlay/internal/mod"
+)
+
+// ModuleStorePath returns the store path of the fixed-output derivation
+// fetchGoModule builds for a remote module. It is named after the path the
+// module is fetched from and its version, and is the same for every Go
+// toolchain, as fetchGoModuleZip produces an identical derivation output.
+func ModuleStorePath(cfg mod.ModuleConfig) (*storepath.StorePath, error) {
+	name := strings.ReplaceAll(cfg.FetchPath(), "/", "-") + "_" + cfg.Version
+	return FixedOutputPath(name, cfg.Hash)
+}
+
+// FixedOutputPath returns the store path of a fixed-output derivation whose
+// output is recursively (NAR) hashed with SHA-256, matching
+// `outputHashMode = "recursive"`. Such paths depend only on the name and
+// hash, never on how the derivation builds its output.
+func FixedOutputPath(name, narHash string) (*storepath.StorePath, error) {
+	h, err := nixhash.ParseAny(narHash, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NAR hash %q: %w", narHash, err)
+	}
+	if h.Algo() != nixhash.SHA256 {
+		return nil, fmt.Errorf("invalid NAR hash %q: must be sha256", narHash)
+	}
+
+	fingerprint := "source:sha256:" + hex.EncodeToString(h.Digest()) + ":" + storepath.StoreDir + ":" + name
+	sum := sha256.Sum256([]byte(fingerprint))
+
+	sp := &storepath.StorePath{
+		Name:   name,
+		Digest: nixhash.CompressHash(sum[:], storepath.PathHashSize),
+	}
+	if err := sp.Validate(); err != nil {
+		return nil, err
+	}
+	return sp, nil
+}
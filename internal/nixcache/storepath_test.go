@@ -0,0 +1,34 @@
+package nixcache_test
+
+import (
+	"testing"
+
+	"github.com/purpleclay/go-overlay/internal/mod"
+	"github.com/purpleclay/go-overlay/internal/nixcache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixedOutputPath(t *testing.T) {
+	// Taken from a recursive sha256 fixed-output derivation built by Nix.
+	sp, err := nixcache.FixedOutputPath("bar", "sha256-CIE8vumQPGK+TFAncmpBijANpFALLTadOvkob0gVzro=")
+	require.NoError(t, err)
+	assert.Equal(t, "/nix/store/4q0pg5zpfmznxscq3avycvf9xdvx50n3-bar", sp.Absolute())
+}
+
+func TestFixedOutputPathRejectsOtherAlgorithms(t *testing.T) {
+	_, err := nixcache.FixedOutputPath("bar", "sha1-C+7Hteo/D9vJXQ3UfzxbwnXaijM=")
+	require.ErrorContains(t, err, "must be sha256")
+}
+
+func TestModuleStorePath(t *testing.T) {
+	hash := "sha256-CIE8vumQPGK+TFAncmpBijANpFALLTadOvkob0gVzro="
+
+	sp, err := nixcache.ModuleStorePath(mod.ModuleConfig{Path: "github.com/a/b", Version: "v1.2.0+incompatible", Hash: hash})
+	require.NoError(t, err)
+	assert.Equal(t, "github.com-a-b_v1.2.0+incompatible", sp.Name)
+
+	sp, err = nixcache.ModuleStorePath(mod.ModuleConfig{Path: "github.com/a/b", Version: "v1.0.0", Hash: hash, ReplacedPath: "github.com/fork/b"})
+	require.NoError(t, err)
+	assert.Equal(t, "github.com-fork-b_v1.0.0", sp.Name)
+}
@@ -0,0 +1,82 @@
+package notes
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+)
+
+type collectorKey struct{}
+
+// Collector gathers notable events, such as retried commands, that occur
+// while processing a single path, and tracks which phases of processing are
+// currently running. It is carried through a context so deeply nested code
+// can report events without threading extra return values.
+type Collector struct {
+	mu     sync.Mutex
+	notes  []string
+	active map[int]string
+	nextID int
+}
+
+// WithCollector returns a child context carrying a new Collector.
+func WithCollector(ctx context.Context) (context.Context, *Collector) {
+	c := &Collector{active: make(map[int]string)}
+	return context.WithValue(ctx, collectorKey{}, c), c
+}
+
+// Add formats and records a note against the Collector carried by ctx. It is
+// a no-op when ctx carries no Collector.
+func Add(ctx context.Context, format string, args ...any) {
+	c, ok := ctx.Value(collectorKey{}).(*Collector)
+	if !ok {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.notes = append(c.notes, fmt.Sprintf(format, args...))
+}
+
+// Notes returns a copy of all recorded notes in the order they were added.
+func (c *Collector) Notes() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.notes...)
+}
+
+// Begin marks phase as running against the Collector carried by ctx and
+// returns a function that marks it as finished. Phases may overlap.
+func Begin(ctx context.Context, phase string) func() {
+	c, ok := ctx.Value(collectorKey{}).(*Collector)
+	if !ok {
+		return func() {}
+	}
+
+	c.mu.Lock()
+	id := c.nextID
+	c.nextID++
+	c.active[id] = phase
+	c.mu.Unlock()
+
+	return func() {
+		c.mu.Lock()
+		delete(c.active, id)
+		c.mu.Unlock()
+	}
+}
+
+// Active returns the phases currently running, in the order they began.
+func (c *Collector) Active() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ids := slices.Sorted(maps.Keys(c.active))
+	phases := make([]string, 0, len(ids))
+	for _, id := range ids {
+		phases = append(phases, c.active[id])
+	}
+	return phases
+}
@@ -0,0 +1,38 @@
+package notes_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/purpleclay/go-overlay/internal/notes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddRecordsAgainstCollector(t *testing.T) {
+	ctx, c := notes.WithCollector(context.Background())
+
+	notes.Add(ctx, "retried %s (attempt %d/%d)", "go list", 2, 3)
+	notes.Add(ctx, "second note")
+
+	assert.Equal(t, []string{"retried go list (attempt 2/3)", "second note"}, c.Notes())
+}
+
+func TestAddWithoutCollectorIsNoOp(t *testing.T) {
+	assert.NotPanics(t, func() {
+		notes.Add(context.Background(), "ignored")
+	})
+}
+
+func TestBeginTracksActivePhases(t *testing.T) {
+	ctx, c := notes.WithCollector(context.Background())
+
+	endDownload := notes.Begin(ctx, "go mod download")
+	endList := notes.Begin(ctx, "go list (linux/amd64)")
+	assert.Equal(t, []string{"go mod download", "go list (linux/amd64)"}, c.Active())
+
+	endDownload()
+	assert.Equal(t, []string{"go list (linux/amd64)"}, c.Active())
+
+	endList()
+	assert.Empty(t, c.Active())
+}
@@ -0,0 +1,163 @@
+// Package overlay describes the Go toolchains go-overlay provides, so a
+// go.mod can be checked against them before a Nix evaluation fails.
+package overlay
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"go/version"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const versionsHeader = "# Auto-generated by goscrape - do not edit manually\n"
+
+// versions lists every Go version with a manifest under manifests/go,
+// regenerated by `goscrape go-dev generate --versions-file`.
+//
+//go:embed versions.txt
+var versions string
+
+// Versions returns every Go version go-overlay provides, oldest first, such
+// as "1.25.4" or "1.26rc1".
+func Versions() []string {
+	return ParseVersions(versions)
+}
+
+// ParseVersions parses a versions file, one version per line, skipping
+// comments and blank lines.
+func ParseVersions(data string) []string {
+	var parsed []string
+	for line := range strings.SplitSeq(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parsed = append(parsed, line)
+	}
+	return parsed
+}
+
+// ScanManifests returns the Go versions of the manifests in dir, each named
+// after its version, such as 1.25.4.nix.
+func ScanManifests(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest directory: %w", err)
+	}
+
+	var found []string
+	for _, e := range entries {
+		v, ok := strings.CutSuffix(e.Name(), ".nix")
+		if e.IsDir() || !ok || !version.IsValid("go"+v) {
+			continue
+		}
+		found = append(found, v)
+	}
+	return found, nil
+}
+
+// FormatVersions renders versions as a versions file, oldest first.
+func FormatVersions(versions []string) []byte {
+	sorted := slices.Clone(versions)
+	slices.SortFunc(sorted, compare)
+
+	var buf bytes.Buffer
+	buf.WriteString(versionsHeader)
+	for _, v := range sorted {
+		buf.WriteString(v + "\n")
+	}
+	return buf.Bytes()
+}
+
+var (
+	goDirective        = regexp.MustCompile(`^go ([0-9]+\.[0-9]+((\.[0-9]+)|(rc[0-9]+))?)$`)
+	toolchainDirective = regexp.MustCompile(`^toolchain (go[0-9]+\.[0-9]+((\.[0-9]+)|(rc[0-9]+)))$`)
+)
+
+// ParseDirectives returns the go and toolchain directives of a go.mod or
+// go.work as go-bin.fromGoMod reads them, such as "1.25.4" and "go1.25.5".
+// Unlike the go command, it only matches a directive alone on its line, so
+// one that is indented, followed by a comment, or ends with a carriage
+// return is not found.
+func ParseDirectives(data string) (goVersion, toolchain string) {
+	for line := range strings.SplitSeq(data, "\n") {
+		if m := goDirective.FindStringSubmatch(line); m != nil && goVersion == "" {
+			goVersion = m[1]
+		}
+		if m := toolchainDirective.FindStringSubmatch(line); m != nil && toolchain == "" {
+			toolchain = m[1]
+		}
+	}
+	return goVersion, toolchain
+}
+
+// Select returns the version go-bin.fromGoMod selects for the go and
+// toolchain directives of a go.mod or go.work, such as "1.25.4" and
+// "go1.25.5". A toolchain directive must match an available version
+// exactly, otherwise the go directive must, unless it names just a language
+// version, such as "1.25", which selects its latest patch release.
+func Select(available []string, goVersion, toolchain string) (string, bool) {
+	if toolchain != "" {
+		v := strings.TrimPrefix(toolchain, "go")
+		return v, slices.Contains(available, v)
+	}
+
+	if slices.Contains(available, goVersion) {
+		return goVersion, true
+	}
+
+	var latest string
+	for _, v := range available {
+		if strings.HasPrefix(v, goVersion+".") && (latest == "" || compare(v, latest) > 0) {
+			latest = v
+		}
+	}
+	return latest, latest != ""
+}
+
+// Nearest returns the available version closest to v: the latest patch
+// release of the same language version, otherwise the oldest newer release,
+// otherwise the newest release. Pre-releases are only suggested for a
+// pre-release.
+func Nearest(available []string, v string) string {
+	lang := version.Lang("go" + v)
+	prerelease := !isRelease(v)
+
+	var sameLang, newer, newest string
+	for _, candidate := range available {
+		if !prerelease && !isRelease(candidate) {
+			continue
+		}
+		if version.Lang("go"+candidate) == lang && (sameLang == "" || compare(candidate, sameLang) > 0) {
+			sameLang = candidate
+		}
+		if compare(candidate, v) > 0 && (newer == "" || compare(candidate, newer) < 0) {
+			newer = candidate
+		}
+		if newest == "" || compare(candidate, newest) > 0 {
+			newest = candidate
+		}
+	}
+
+	switch {
+	case sameLang != "":
+		return sameLang
+	case newer != "":
+		return newer
+	}
+	return newest
+}
+
+// isRelease reports whether v is a release rather than a beta or release
+// candidate.
+func isRelease(v string) bool {
+	return !strings.Contains(v, "rc") && !strings.Contains(v, "beta")
+}
+
+func compare(a, b string) int {
+	return version.Compare("go"+a, "go"+b)
+}
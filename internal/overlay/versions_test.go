@@ -0,0 +1,94 @@
+package overlay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionsMatchManifests(t *testing.T) {
+	scanned, err := ScanManifests("../../manifests/go")
+	require.NoError(t, err)
+
+	assert.Equal(t, string(FormatVersions(scanned)), versions,
+		"versions.txt is stale, run 'goscrape go-dev generate --output manifests/go --versions-file internal/overlay/versions.txt'")
+}
+
+func TestFormatVersions(t *testing.T) {
+	got := FormatVersions([]string{"1.26.0", "1.25.10", "1.26rc1", "1.25.9", "1.25"})
+
+	assert.Equal(t, versionsHeader+"1.25\n1.25.9\n1.25.10\n1.26rc1\n1.26.0\n", string(got))
+	assert.Equal(t, []string{"1.25", "1.25.9", "1.25.10", "1.26rc1", "1.26.0"}, ParseVersions(string(got)))
+}
+
+var available = []string{"1.24.9", "1.24.10", "1.25.0", "1.25.4", "1.25.5", "1.26rc1", "1.26rc2"}
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		name      string
+		goVersion string
+		toolchain string
+		want      string
+		ok        bool
+	}{
+		{name: "ExactGoVersion", goVersion: "1.25.4", want: "1.25.4", ok: true},
+		{name: "LanguageVersionSelectsLatestPatch", goVersion: "1.24", want: "1.24.10", ok: true},
+		{name: "ToolchainTakesPrecedence", goVersion: "1.24.9", toolchain: "go1.25.5", want: "1.25.5", ok: true},
+		{name: "Prerelease", goVersion: "1.26rc2", want: "1.26rc2", ok: true},
+		{name: "MissingPatch", goVersion: "1.25.6", ok: false},
+		{name: "MissingToolchain", goVersion: "1.25.4", toolchain: "go1.25.7", want: "1.25.7", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Select(available, tt.goVersion, tt.toolchain)
+			assert.Equal(t, tt.ok, ok)
+			if tt.want != "" {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseDirectives(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		goVersion string
+		toolchain string
+	}{
+		{name: "GoAndToolchain", data: "module m\n\ngo 1.25.4\n\ntoolchain go1.25.5\n", goVersion: "1.25.4", toolchain: "go1.25.5"},
+		{name: "LanguageVersion", data: "module m\ngo 1.25\n", goVersion: "1.25"},
+		{name: "Prerelease", data: "go 1.26rc1\ntoolchain go1.26rc2\n", goVersion: "1.26rc1", toolchain: "go1.26rc2"},
+		{name: "FirstMatchWins", data: "go 1.24.1\ngo 1.25.0\n", goVersion: "1.24.1"},
+		{name: "TrailingComment", data: "go 1.25.4 // pinned\ntoolchain go1.25.5 // pinned\n"},
+		{name: "CRLF", data: "module m\r\n\r\ngo 1.25.4\r\ntoolchain go1.25.5\r\n"},
+		{name: "Indented", data: "  go 1.25.4\n\ttoolchain go1.25.5\n"},
+		{name: "ToolchainWithoutPatch", data: "go 1.25.4\ntoolchain go1.25\n", goVersion: "1.25.4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goVersion, toolchain := ParseDirectives(tt.data)
+			assert.Equal(t, tt.goVersion, goVersion)
+			assert.Equal(t, tt.toolchain, toolchain)
+		})
+	}
+}
+
+func TestNearest(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{version: "1.25.9", want: "1.25.5"},
+		{version: "1.23.4", want: "1.24.9"},
+		{version: "1.27.0", want: "1.25.5"},
+		{version: "1.26rc3", want: "1.26rc2"},
+		{version: "1.26.0", want: "1.25.5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			assert.Equal(t, tt.want, Nearest(available, tt.version))
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package resolve
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrorClass identifies a well-known class of resolution failure.
+type ErrorClass string
+
+const (
+	ClassAuth                 ErrorClass = "auth"
+	ClassNotFound             ErrorClass = "not-found"
+	ClassChecksumMismatch     ErrorClass = "checksum-mismatch"
+	ClassMissingGoSum         ErrorClass = "missing-go-sum"
+	ClassInvalidPseudoVersion ErrorClass = "invalid-pseudo-version"
+	ClassToolchainTooOld      ErrorClass = "toolchain-too-old"
+	ClassNetworkTimeout       ErrorClass = "network-timeout"
+	ClassGitNotInstalled      ErrorClass = "git-not-installed"
+)
+
+// ClassifiedError is a resolution failure recognised as belonging to a known
+// class. Error returns a condensed summary of the toolchain output; the full
+// output remains available through Unwrap.
+type ClassifiedError struct {
+	Class   ErrorClass
+	Summary string
+	Remedy  string
+	Err     error
+}
+
+func (e *ClassifiedError) Error() string {
+	return e.Summary
+}
+
+func (e *ClassifiedError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorClass returns the failure class as a plain string.
+func (e *ClassifiedError) ErrorClass() string {
+	return string(e.Class)
+}
+
+// Hint returns a short remediation hint for the failure class.
+func (e *ClassifiedError) Hint() string {
+	return e.Remedy
+}
+
+type classifier struct {
+	class   ErrorClass
+	pattern *regexp.Regexp
+	hint    func(match []string) string
+}
+
+func staticHint(hint string) func([]string) string {
+	return func([]string) string { return hint }
+}
+
+// classifiers are evaluated in order and the first match wins. More specific
+// classes come first, as a checksum mismatch or toolchain error may also
+// mention network operations in its output.
+var classifiers = []classifier{
+	{
+		class:   ClassGitNotInstalled,
+		pattern: regexp.MustCompile(`exec: "git": executable file not found`),
+		hint:    staticHint("install git, which is needed for local modules and direct VCS fetches"),
+	},
+	{
+		class:   ClassToolchainTooOld,
+		pattern: regexp.MustCompile(`go\.(?:mod|work) requires go >= (\S+)`),
+		hint: func(match []string) string {
+			return fmt.Sprintf("install go %s or later, or allow GOTOOLCHAIN to switch toolchains", strings.TrimSuffix(match[1], ";"))
+		},
+	},
+	{
+		class:   ClassChecksumMismatch,
+		pattern: regexp.MustCompile(`(?i)checksum mismatch|SECURITY ERROR`),
+		hint:    staticHint("the downloaded module does not match go.sum; verify the upstream source, then run 'go clean -modcache'"),
+	},
+	{
+		class:   ClassMissingGoSum,
+		pattern: regexp.MustCompile(`missing go\.sum entry`),
+		hint:    staticHint("run 'go mod tidy' to add the missing go.sum entries"),
+	},
+	{
+		class:   ClassInvalidPseudoVersion,
+		pattern: regexp.MustCompile(`invalid pseudo-version`),
+		hint:    staticHint("the pseudo-version does not match an upstream commit; re-run 'go get' for the module"),
+	},
+	{
+		class:   ClassAuth,
+		pattern: regexp.MustCompile(`\b(?:401 Unauthorized|403 Forbidden)\b|terminal prompts disabled|could not read Username|[Aa]uthentication failed`),
+		hint:    staticHint("set GOPRIVATE and netrcFile for private modules"),
+	},
+	{
+		class:   ClassNotFound,
+		pattern: regexp.MustCompile(`\b(?:404 Not Found|410 Gone)\b|unknown revision|repository not found`),
+		hint:    staticHint("check the module path and version exist upstream and have not been retracted"),
+	},
+	{
+		class:   ClassNetworkTimeout,
+		pattern: regexp.MustCompile(`i/o timeout|TLS handshake timeout|Client\.Timeout exceeded|context deadline exceeded|no such host|connection refused`),
+		hint:    staticHint("check network access to GOPROXY, or set GOPROXY to a reachable mirror"),
+	},
+}
+
+// Classify inspects err for a known class of resolution failure and, if one
+// is recognised, wraps it in a *ClassifiedError. Unrecognised errors, nil and
+// already classified errors are returned unchanged.
+func Classify(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var classified *ClassifiedError
+	if errors.As(err, &classified) {
+		return err
+	}
+
+	lines := strings.Split(strings.TrimSpace(err.Error()), "\n")
+	for _, c := range classifiers {
+		for _, line := range lines {
+			if match := c.pattern.FindStringSubmatch(line); match != nil {
+				return &ClassifiedError{
+					Class:   c.class,
+					Summary: summarise(lines[0], line),
+					Remedy:  c.hint(match),
+					Err:     err,
+				}
+			}
+		}
+	}
+
+	return err
+}
+
+// summarise keeps the first line of toolchain output, which names the
+// failing module, alongside the line that identified the failure class.
+func summarise(first, matched string) string {
+	first = strings.TrimSpace(first)
+	matched = strings.TrimSpace(matched)
+	if first == matched {
+		return first
+	}
+	return first + " " + matched
+}
@@ -0,0 +1,120 @@
+package resolve
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name    string
+		stderr  string
+		class   ErrorClass
+		summary string
+		hint    string
+	}{
+		{
+			name: "PrivateHostUnauthorised",
+			stderr: `go: git.example.com/team/lib@v1.2.0: reading https://proxy.golang.org/git.example.com/team/lib/@v/v1.2.0.mod: 401 Unauthorized
+	server response: not authorised`,
+			class:   ClassAuth,
+			summary: "go: git.example.com/team/lib@v1.2.0: reading https://proxy.golang.org/git.example.com/team/lib/@v/v1.2.0.mod: 401 Unauthorized",
+			hint:    "set GOPRIVATE and netrcFile for private modules",
+		},
+		{
+			name: "GitPromptDisabled",
+			stderr: `go: github.com/acme/private@v1.0.0: git ls-remote -q origin in /tmp/vcs/52aff0: exit status 128:
+	fatal: could not read Username for 'https://github.com': terminal prompts disabled`,
+			class:   ClassAuth,
+			summary: "go: github.com/acme/private@v1.0.0: git ls-remote -q origin in /tmp/vcs/52aff0: exit status 128: fatal: could not read Username for 'https://github.com': terminal prompts disabled",
+			hint:    "set GOPRIVATE and netrcFile for private modules",
+		},
+		{
+			name:    "Gone",
+			stderr:  `go: example.com/lib@v0.3.0: reading https://proxy.golang.org/example.com/lib/@v/v0.3.0.zip: 410 Gone`,
+			class:   ClassNotFound,
+			summary: "go: example.com/lib@v0.3.0: reading https://proxy.golang.org/example.com/lib/@v/v0.3.0.zip: 410 Gone",
+			hint:    "check the module path and version exist upstream and have not been retracted",
+		},
+		{
+			name: "ChecksumMismatch",
+			stderr: `verifying github.com/fatih/color@v1.18.0: checksum mismatch
+	downloaded: h1:aaaa
+	go.sum:     h1:bbbb
+
+SECURITY ERROR`,
+			class:   ClassChecksumMismatch,
+			summary: "verifying github.com/fatih/color@v1.18.0: checksum mismatch",
+			hint:    "the downloaded module does not match go.sum; verify the upstream source, then run 'go clean -modcache'",
+		},
+		{
+			name:    "MissingGoSum",
+			stderr:  `main.go:4:2: missing go.sum entry for module providing package github.com/fatih/color (imported by example.com/app); to add:`,
+			class:   ClassMissingGoSum,
+			summary: "main.go:4:2: missing go.sum entry for module providing package github.com/fatih/color (imported by example.com/app); to add:",
+			hint:    "run 'go mod tidy' to add the missing go.sum entries",
+		},
+		{
+			name:    "InvalidPseudoVersion",
+			stderr:  `go: example.com/lib@v0.0.0-20240101000000-abcdefabcdef: invalid pseudo-version: does not match version-control timestamp`,
+			class:   ClassInvalidPseudoVersion,
+			summary: "go: example.com/lib@v0.0.0-20240101000000-abcdefabcdef: invalid pseudo-version: does not match version-control timestamp",
+			hint:    "the pseudo-version does not match an upstream commit; re-run 'go get' for the module",
+		},
+		{
+			name:    "ToolchainTooOld",
+			stderr:  `go: go.mod requires go >= 1.26.3 (running go 1.25.0; GOTOOLCHAIN=local)`,
+			class:   ClassToolchainTooOld,
+			summary: "go: go.mod requires go >= 1.26.3 (running go 1.25.0; GOTOOLCHAIN=local)",
+			hint:    "install go 1.26.3 or later, or allow GOTOOLCHAIN to switch toolchains",
+		},
+		{
+			name:    "NetworkTimeout",
+			stderr:  `go: github.com/fatih/color@v1.18.0: Get "https://proxy.golang.org/github.com/fatih/color/@v/v1.18.0.zip": dial tcp 142.250.200.17:443: i/o timeout`,
+			class:   ClassNetworkTimeout,
+			summary: `go: github.com/fatih/color@v1.18.0: Get "https://proxy.golang.org/github.com/fatih/color/@v/v1.18.0.zip": dial tcp 142.250.200.17:443: i/o timeout`,
+			hint:    "check network access to GOPROXY, or set GOPROXY to a reachable mirror",
+		},
+		{
+			name:    "GitNotInstalled",
+			stderr:  `exec: "git": executable file not found in $PATH`,
+			class:   ClassGitNotInstalled,
+			summary: `exec: "git": executable file not found in $PATH`,
+			hint:    "install git, which is needed for local modules and direct VCS fetches",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			execErr := &ExecError{Err: errors.New("exit status 1"), Stderr: tt.stderr}
+
+			err := Classify(execErr)
+
+			var classified *ClassifiedError
+			require.ErrorAs(t, err, &classified)
+			assert.Equal(t, tt.class, classified.Class)
+			assert.Equal(t, tt.summary, classified.Error())
+			assert.Equal(t, tt.hint, classified.Hint())
+			assert.ErrorIs(t, err, execErr)
+		})
+	}
+}
+
+func TestClassifyLeavesUnrecognisedErrorsUnchanged(t *testing.T) {
+	err := errors.New("something unexpected happened")
+	assert.Same(t, err, Classify(err))
+	assert.NoError(t, Classify(nil))
+}
+
+func TestClassifyDoesNotWrapTwice(t *testing.T) {
+	first := Classify(errors.New("410 Gone"))
+	second := Classify(fmt.Errorf("resolve: %w", first))
+
+	var classified *ClassifiedError
+	require.ErrorAs(t, second, &classified)
+	assert.Same(t, first, classified)
+}
@@ -16,7 +16,17 @@ type ModuleDownload struct {
 	Version string `json:"Version"`
 	Dir     string `json:"Dir"`
 	GoMod   string `json:"GoMod"`
+	Zip     string `json:"Zip"`
 	Error   string `json:"Error"`
+
+	// URL and ZipHash locate the zip on a module proxy, when recorded by
+	// WithZipURLs.
+	URL     string `json:"-"`
+	ZipHash string `json:"-"`
+
+	// NARHash is the hash of a module hashed from its zip as it was
+	// fetched by WithProxy, which is never extracted.
+	NARHash string `json:"-"`
 }
 
 // ParseDownloadOutput parses the JSON stream output of `go mod download -json`.
@@ -0,0 +1,102 @@
+package resolve
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// hermeticAllowList names the variables passed through to child processes in
+// hermetic mode. They locate tools, caches and credentials, or select where
+// modules are fetched from, but cannot change which packages or versions the
+// Go toolchain resolves.
+var hermeticAllowList = []string{
+	"PATH",
+	"HOME",
+	"TMPDIR",
+	"GOPATH",
+	"GOMODCACHE",
+	"GOCACHE",
+	"GOPROXY",
+	"GOPRIVATE",
+	"GONOSUMDB",
+	"GONOPROXY",
+	"GOSUMDB",
+	"GOAUTH",
+	"GOTOOLCHAIN",
+	"NETRC",
+	"HTTP_PROXY",
+	"HTTPS_PROXY",
+	"NO_PROXY",
+	"SSL_CERT_FILE",
+	"SSL_CERT_DIR",
+	"SSH_AUTH_SOCK",
+	"GIT_SSH_COMMAND",
+}
+
+// hermeticFixed is always set in hermetic mode. GOENV=off stops the Go
+// command from reading settings persisted with `go env -w`, which would
+// otherwise bypass the allow-list.
+var hermeticFixed = []string{
+	"GOENV=off",
+	"GOFLAGS=",
+}
+
+// HermeticEnviron filters environ (in os.Environ form) down to the hermetic
+// allow-list and appends the fixed hermetic settings.
+func HermeticEnviron(environ []string) []string {
+	filtered := make([]string, 0, len(hermeticAllowList)+len(hermeticFixed))
+	for _, kv := range environ {
+		key, _, _ := strings.Cut(kv, "=")
+		if slices.Contains(hermeticAllowList, key) {
+			filtered = append(filtered, kv)
+		}
+	}
+	return append(filtered, hermeticFixed...)
+}
+
+// goEnvKeys are the Go settings that influence module resolution, reported
+// by GoEnv so the effective environment can be audited.
+var goEnvKeys = []string{
+	"GOVERSION",
+	"GOTOOLCHAIN",
+	"GOFLAGS",
+	"GOEXPERIMENT",
+	"GOPROXY",
+	"GOPRIVATE",
+	"GONOPROXY",
+	"GONOSUMDB",
+	"GOSUMDB",
+	"GOMODCACHE",
+}
+
+// GoEnv reports the effective Go settings that influence module resolution,
+// as seen by commands run through exec, in KEY=value form.
+func GoEnv(ctx context.Context, exec Executor) ([]string, error) {
+	out, err := exec.Run(ctx, append([]string{"go", "env"}, goEnvKeys...), ".", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read go environment: %w", err)
+	}
+
+	values := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	if len(values) != len(goEnvKeys) {
+		return nil, fmt.Errorf("unexpected go env output: expected %d values, got %d", len(goEnvKeys), len(values))
+	}
+
+	env := make([]string, len(goEnvKeys))
+	for i, key := range goEnvKeys {
+		env[i] = key + "=" + values[i]
+	}
+	return env, nil
+}
+
+// PrintGoEnv writes the go environment reported by GoEnv to w, one setting
+// per line, as printed by commands run in hermetic mode.
+func PrintGoEnv(w io.Writer, env []string) {
+	fmt.Fprintln(w, "hermetic go environment:")
+	for _, kv := range env {
+		fmt.Fprintln(w, "  "+kv)
+	}
+}
@@ -0,0 +1,76 @@
+package resolve
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHermeticEnviron(t *testing.T) {
+	environ := []string{
+		"PATH=/usr/bin",
+		"HOME=/home/dev",
+		"GOFLAGS=-mod=vendor",
+		"GOEXPERIMENT=rangefunc",
+		"GONOSUMCHECK=1",
+		"GOPROXY=https://proxy.example.com",
+		"GOPRIVATE=git.example.com",
+		"EDITOR=vim",
+	}
+
+	assert.Equal(t, []string{
+		"PATH=/usr/bin",
+		"HOME=/home/dev",
+		"GOPROXY=https://proxy.example.com",
+		"GOPRIVATE=git.example.com",
+		"GOENV=off",
+		"GOFLAGS=",
+	}, HermeticEnviron(environ))
+}
+
+func TestOSExecutorHermeticIgnoresShellGoFlags(t *testing.T) {
+	t.Setenv("GOFLAGS", "-mod=vendor")
+	t.Setenv("GOEXPERIMENT", "")
+
+	out, err := OSExecutor{Hermetic: true}.Run(context.Background(), []string{"go", "env", "GOFLAGS"}, ".", nil)
+	require.NoError(t, err)
+	assert.Empty(t, strings.TrimSpace(out))
+
+	out, err = OSExecutor{}.Run(context.Background(), []string{"go", "env", "GOFLAGS"}, ".", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "-mod=vendor", strings.TrimSpace(out))
+}
+
+func TestGoEnv(t *testing.T) {
+	exec := &fakeExecutor{
+		responses: map[string]string{
+			"go env": "go1.26.3\nauto\n\n\nhttps://proxy.golang.org,direct\ngit.example.com\ngit.example.com\ngit.example.com\nsum.golang.org\n/home/dev/go/pkg/mod\n",
+		},
+	}
+
+	env, err := GoEnv(context.Background(), exec)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"GOVERSION=go1.26.3",
+		"GOTOOLCHAIN=auto",
+		"GOFLAGS=",
+		"GOEXPERIMENT=",
+		"GOPROXY=https://proxy.golang.org,direct",
+		"GOPRIVATE=git.example.com",
+		"GONOPROXY=git.example.com",
+		"GONOSUMDB=git.example.com",
+		"GOSUMDB=sum.golang.org",
+		"GOMODCACHE=/home/dev/go/pkg/mod",
+	}, env)
+}
+
+func TestPrintGoEnv(t *testing.T) {
+	var buf bytes.Buffer
+	PrintGoEnv(&buf, []string{"GOVERSION=go1.26.3", "GOFLAGS="})
+
+	assert.Equal(t, "hermetic go environment:\n  GOVERSION=go1.26.3\n  GOFLAGS=\n", buf.String())
+}
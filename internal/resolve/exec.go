@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
+	"sync"
+	"time"
 )
 
 // Executor runs external commands and returns their stdout. The interface
@@ -33,25 +36,84 @@ func (e *ExecError) Unwrap() error {
 	return e.Err
 }
 
-// OSExecutor runs commands using os/exec.
-type OSExecutor struct{}
+// OSExecutor runs commands using os/exec. By default each command inherits
+// the full environment of the current process.
+type OSExecutor struct {
+	// Hermetic builds the child environment from HermeticEnviron instead, so
+	// variables such as GOFLAGS or GOEXPERIMENT in a developer's shell cannot
+	// change the generated manifest.
+	Hermetic bool
+}
 
-func (OSExecutor) Run(ctx context.Context, args []string, dir string, env []string) (string, error) {
+func (e OSExecutor) Run(ctx context.Context, args []string, dir string, env []string) (string, error) {
 	if len(args) == 0 {
 		return "", fmt.Errorf("empty command")
 	}
 
+	base := os.Environ()
+	if e.Hermetic {
+		base = HermeticEnviron(base)
+	}
+
 	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
 	cmd.Dir = dir
-	cmd.Env = append(os.Environ(), env...)
+	cmd.Env = append(base, env...)
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
 	if err := cmd.Run(); err != nil {
+		// A command killed by the context reports only its exit signal.
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = ctxErr
+		}
 		return "", &ExecError{Err: err, Stderr: stderr.String()}
 	}
 
 	return stdout.String(), nil
 }
+
+// Logger serialises verbose diagnostic lines written from the resolver's
+// concurrent goroutines. A nil *Logger discards everything.
+type Logger struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewLogger creates a Logger that writes to w.
+func NewLogger(w io.Writer) *Logger {
+	return &Logger{w: w}
+}
+
+// Printf formats and writes a single line.
+func (l *Logger) Printf(format string, args ...any) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fmt.Fprintf(l.w, format+"\n", args...)
+}
+
+// VerboseExecutor decorates an Executor, logging every invocation with its
+// working directory, environment overrides, duration and outcome.
+type VerboseExecutor struct {
+	Exec Executor
+	Log  *Logger
+}
+
+func (v VerboseExecutor) Run(ctx context.Context, args []string, dir string, env []string) (string, error) {
+	start := time.Now()
+	out, err := v.Exec.Run(ctx, args, dir, env)
+	elapsed := time.Since(start).Round(time.Millisecond)
+
+	status := "ok"
+	if err != nil {
+		status = "failed"
+	}
+	v.Log.Printf("exec: %s (dir=%s env=[%s]) %s in %s", strings.Join(args, " "), dir, strings.Join(env, " "), status, elapsed)
+
+	return out, err
+}
@@ -0,0 +1,49 @@
+package resolve
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerboseExecutorLogsInvocation(t *testing.T) {
+	var buf bytes.Buffer
+	exec := VerboseExecutor{
+		Exec: &fakeExecutor{responses: map[string]string{"go mod download -json": "{}"}},
+		Log:  NewLogger(&buf),
+	}
+
+	out, err := exec.Run(context.Background(), []string{"go", "mod", "download", "-json"}, "/src/app", []string{"GOWORK=off"})
+	require.NoError(t, err)
+	assert.Equal(t, "{}", out)
+
+	line := buf.String()
+	assert.Contains(t, line, "exec: go mod download -json")
+	assert.Contains(t, line, "dir=/src/app")
+	assert.Contains(t, line, "env=[GOWORK=off]")
+	assert.Contains(t, line, "ok in")
+}
+
+func TestVerboseExecutorLogsFailure(t *testing.T) {
+	var buf bytes.Buffer
+	exec := VerboseExecutor{
+		Exec: &fakeExecutor{},
+		Log:  NewLogger(&buf),
+	}
+
+	_, err := exec.Run(context.Background(), []string{"git", "ls-files"}, ".", nil)
+	require.Error(t, err)
+	assert.Contains(t, buf.String(), "exec: git ls-files (dir=. env=[]) failed in")
+}
+
+func TestOSExecutorReportsDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := OSExecutor{}.Run(ctx, []string{"sleep", "10"}, ".", nil)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
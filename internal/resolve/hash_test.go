@@ -1,6 +1,7 @@
 package resolve_test
 
 import (
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -8,6 +9,8 @@ import (
 	"github.com/purpleclay/go-overlay/internal/resolve"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/module"
+	modzip "golang.org/x/mod/zip"
 )
 
 func TestNARHash(t *testing.T) {
@@ -23,3 +26,38 @@ func TestNARHashFiltered(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "sha256-OtzhFGcov9e8CDxmuKGQIlxXHLMKclkhjgekIe56Uks=", hash)
 }
+
+func TestNARHashZipMatchesExtractedModule(t *testing.T) {
+	src := t.TempDir()
+	files := map[string]os.FileMode{
+		"go.mod":           0o644,
+		"main.go":          0o644,
+		"a.go":             0o644,
+		"a/b.go":           0o644,
+		"a-b/c.go":         0o644,
+		"a/deep/nested.go": 0o644,
+		"scripts/run.sh":   0o755,
+	}
+	for name, perm := range files {
+		path := filepath.Join(src, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte("// "+name+"\n"), perm))
+	}
+
+	m := module.Version{Path: "example.com/zipped", Version: "v1.0.0"}
+	zipFile := filepath.Join(t.TempDir(), "v1.0.0.zip")
+	f, err := os.Create(zipFile)
+	require.NoError(t, err)
+	require.NoError(t, modzip.CreateFromDir(f, m, src))
+	require.NoError(t, f.Close())
+
+	extracted := filepath.Join(t.TempDir(), "zipped@v1.0.0")
+	require.NoError(t, modzip.Unzip(extracted, m, zipFile))
+
+	want, err := resolve.NARHash(extracted)
+	require.NoError(t, err)
+
+	got, err := resolve.NARHashZip(zipFile, m)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
@@ -0,0 +1,310 @@
+package resolve
+
+import (
+	"errors"
+	"fmt"
+	"go/build"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/purpleclay/go-overlay/internal/mod"
+)
+
+// packageIndex locates the source directory of packages across the main
+// modules and the modules they depend on, so import closures can be
+// computed with go/build rather than `go list`.
+type packageIndex struct {
+	mains   map[string]string
+	modules map[string]string
+	tools   []string
+
+	// ignores holds the ignore directives of each main module, keyed by
+	// module path.
+	ignores map[string][]string
+
+	// selection restricts the main packages whose imports are followed.
+	selection packageSelection
+
+	// includeMains records packages of the main modules alongside those of
+	// dependencies, as listing a workspace does.
+	includeMains bool
+}
+
+// closure computes the equivalent of `go list -deps -test ./...` across the
+// main modules, plus `go list -deps tool`, for a single platform. Packages
+// are grouped by the path of the module providing them, each recording the
+// scope that reaches it: imports of the main packages are needed by the
+// build (and by their tests), test imports by tests alone, and tools by the
+// tool scope. Standard library packages are skipped. Each package also
+// records whether it uses cgo or embeds files.
+func (idx *packageIndex) closure(goos, goarch string) (modulePackages, error) {
+	bctx := build.Default
+	bctx.GOOS = goos
+	bctx.GOARCH = goarch
+	// Cgo is always enabled, as for `go list`, so cgo files are seen for
+	// every platform whatever the host and its C compiler.
+	bctx.CgoEnabled = true
+
+	type pending struct {
+		importPath string
+		scope      scope
+	}
+
+	// loaded caches what is needed of a package once it has been imported,
+	// as it can be revisited in a wider scope.
+	type loaded struct {
+		imports  []string
+		features feature
+	}
+
+	var (
+		pkgsByMod = make(modulePackages)
+		visited   = make(map[string]scope)
+		packages  = make(map[string]loaded)
+		queue     []pending
+	)
+
+	enqueue := func(importPaths []string, s scope) {
+		for _, importPath := range importPaths {
+			queue = append(queue, pending{importPath: importPath, scope: s})
+		}
+	}
+
+	record := func(modPath, importPath string, s scope, f feature) {
+		if idx.includeMains || idx.mains[modPath] == "" {
+			pkgsByMod.add(modPath, importPath, s, f)
+		}
+	}
+
+	for modPath, dir := range idx.mains {
+		pkgs, err := mainPackages(modPath, dir, idx.ignores[modPath])
+		if err != nil {
+			return nil, err
+		}
+
+		for _, pkg := range pkgs {
+			if !idx.selection.matches(pkg) {
+				continue
+			}
+
+			bp, err := bctx.ImportDir(pkg.dir, 0)
+			if err != nil {
+				var noGo *build.NoGoError
+				if errors.As(err, &noGo) {
+					continue
+				}
+				return nil, fmt.Errorf("failed to load package %s: %w", pkg.importPath, err)
+			}
+
+			visited[pkg.importPath] = scopeBuild | scopeTest
+			record(modPath, pkg.importPath, scopeBuild|scopeTest, packageFeatures(bp))
+			enqueue(bp.Imports, scopeBuild|scopeTest)
+			enqueue(bp.TestImports, scopeTest)
+			enqueue(bp.XTestImports, scopeTest)
+		}
+	}
+	enqueue(idx.tools, scopeTool)
+
+	// A package is revisited whenever it is reached in a scope it was not
+	// reached in before, so the scope propagates to its imports.
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+
+		importPath, s := next.importPath, next.scope
+		if isStandardImport(importPath) || visited[importPath]&s == s {
+			continue
+		}
+
+		modPath, dir, ok := idx.lookup(importPath)
+		if !ok {
+			return nil, fmt.Errorf("no required module provides package %s", importPath)
+		}
+
+		pkg, ok := packages[importPath]
+		if !ok {
+			bp, err := bctx.ImportDir(dir, 0)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load package %s for %s/%s: %w", importPath, goos, goarch, err)
+			}
+			pkg = loaded{imports: bp.Imports, features: packageFeatures(bp)}
+			packages[importPath] = pkg
+		}
+
+		s |= visited[importPath]
+		visited[importPath] = s
+		record(modPath, importPath, s, pkg.features)
+		enqueue(pkg.imports, s)
+	}
+
+	return pkgsByMod, nil
+}
+
+// lookup finds the module providing importPath. When module paths nest,
+// the longest one with a matching directory containing Go files wins.
+func (idx *packageIndex) lookup(importPath string) (modPath, dir string, ok bool) {
+	best := ""
+	for _, candidates := range []map[string]string{idx.mains, idx.modules} {
+		for path, root := range candidates {
+			if len(path) <= len(best) {
+				continue
+			}
+			rel, found := strings.CutPrefix(importPath, path)
+			if !found || (rel != "" && rel[0] != '/') {
+				continue
+			}
+			if pkgDir := filepath.Join(root, filepath.FromSlash(rel)); hasGoFiles(pkgDir) {
+				best, dir = path, pkgDir
+			}
+		}
+	}
+	return best, dir, best != ""
+}
+
+type mainPackage struct {
+	importPath string
+	dir        string
+}
+
+// mainPackages walks a main module for package directories matched by the
+// "./..." pattern. Directories named testdata or vendor, those starting
+// with "." or "_", those excluded by the module's ignore directives, and
+// nested modules are skipped.
+func mainPackages(modPath, root string, ignores []string) ([]mainPackage, error) {
+	var pkgs []mainPackage
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+
+		if path != root {
+			name := d.Name()
+			if name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+				return filepath.SkipDir
+			}
+			if rel, err := filepath.Rel(root, path); err == nil && mod.IsIgnored(ignores, filepath.ToSlash(rel)) {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+		}
+
+		if !hasGoFiles(path) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		importPath := modPath
+		if rel != "." {
+			importPath += "/" + filepath.ToSlash(rel)
+		}
+		pkgs = append(pkgs, mainPackage{importPath: importPath, dir: path})
+		return nil
+	})
+	return pkgs, err
+}
+
+// packageSelection restricts resolution to the main packages matching a set
+// of `go list` package patterns. Relative patterns, such as "./cmd/...", are
+// interpreted from dir, and others match import paths. As with the go
+// command, "..." matches any string, and a trailing "/..." also matches the
+// path before it. Without patterns every main package matches, unless
+// toolsOnly is set, as when resolving an alternate modfile such as tools.mod.
+type packageSelection struct {
+	dir       string
+	patterns  []string
+	toolsOnly bool
+}
+
+func (s packageSelection) matches(pkg mainPackage) bool {
+	if len(s.patterns) == 0 {
+		return !s.toolsOnly
+	}
+
+	rel, err := filepath.Rel(s.dir, pkg.dir)
+	if err != nil {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+
+	for _, pattern := range s.patterns {
+		target := pkg.importPath
+		if pattern == "." || pattern == ".." || strings.HasPrefix(pattern, "./") || strings.HasPrefix(pattern, "../") {
+			pattern = path.Clean(pattern)
+			target = rel
+		}
+		if matchPattern(pattern, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPattern reports whether name matches a package pattern. Each "..."
+// is matched against the shortest span of name that lets the rest of the
+// pattern follow it, so no regexp is compiled per package.
+func matchPattern(pattern, name string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "/..."); ok && matchPattern(prefix, name) {
+		return true
+	}
+
+	parts := strings.Split(pattern, "...")
+	rest, ok := strings.CutPrefix(name, parts[0])
+	if !ok {
+		return false
+	}
+	if len(parts) == 1 {
+		return rest == ""
+	}
+
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(rest, part)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(part):]
+	}
+	return strings.HasSuffix(rest, parts[len(parts)-1])
+}
+
+func hasGoFiles(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	return slices.ContainsFunc(entries, func(e fs.DirEntry) bool {
+		return e.Type().IsRegular() && strings.HasSuffix(e.Name(), ".go")
+	})
+}
+
+// packageFeatures returns the features used by a package, as `go list`
+// reports them through CgoFiles and EmbedPatterns.
+func packageFeatures(bp *build.Package) feature {
+	var f feature
+	if len(bp.CgoFiles) > 0 {
+		f |= featureCgo
+	}
+	if len(bp.EmbedPatterns) > 0 {
+		f |= featureEmbed
+	}
+	return f
+}
+
+// isStandardImport reports whether importPath belongs to the standard
+// library, or is the cgo pseudo-package "C". As with the go command, these
+// are paths whose first element contains no dot.
+func isStandardImport(importPath string) bool {
+	first, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(first, ".")
+}
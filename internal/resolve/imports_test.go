@@ -0,0 +1,138 @@
+package resolve
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackageSelectionMatches(t *testing.T) {
+	selection := packageSelection{
+		dir:      "/src/app",
+		patterns: []string{"./cmd/server", "./internal/...", "example.com/app/pkg/...", "example.com/app/tools/gen"},
+	}
+
+	tests := []struct {
+		importPath string
+		dir        string
+		want       bool
+	}{
+		{importPath: "example.com/app", dir: "/src/app", want: false},
+		{importPath: "example.com/app/cmd/server", dir: "/src/app/cmd/server", want: true},
+		{importPath: "example.com/app/cmd/server/config", dir: "/src/app/cmd/server/config", want: false},
+		{importPath: "example.com/app/cmd/cli", dir: "/src/app/cmd/cli", want: false},
+		{importPath: "example.com/app/internal", dir: "/src/app/internal", want: true},
+		{importPath: "example.com/app/internal/store", dir: "/src/app/internal/store", want: true},
+		{importPath: "example.com/app/internalx", dir: "/src/app/internalx", want: false},
+		{importPath: "example.com/app/pkg/log", dir: "/src/app/pkg/log", want: true},
+		{importPath: "example.com/app/tools/gen", dir: "/src/app/tools/gen", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.importPath, func(t *testing.T) {
+			assert.Equal(t, tt.want, selection.matches(mainPackage{importPath: tt.importPath, dir: tt.dir}))
+		})
+	}
+}
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{pattern: "example.com/app", name: "example.com/app", want: true},
+		{pattern: "example.com/app", name: "example.com/app/cmd", want: false},
+		{pattern: "example.com/app/...", name: "example.com/app", want: true},
+		{pattern: "example.com/app/...", name: "example.com/app/cmd/server", want: true},
+		{pattern: "example.com/app/...", name: "example.com/appx", want: false},
+		{pattern: "example.com/.../server", name: "example.com/app/cmd/server", want: true},
+		{pattern: "example.com/.../server", name: "example.com/app/cmd/client", want: false},
+		{pattern: "cmd/...er", name: "cmd/server", want: true},
+		{pattern: "...", name: "anything", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+"@"+tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, matchPattern(tt.pattern, tt.name))
+		})
+	}
+}
+
+func TestPackageSelectionMatchesEverythingWithoutPatterns(t *testing.T) {
+	assert.True(t, packageSelection{}.matches(mainPackage{importPath: "example.com/app", dir: "/src/app"}))
+}
+
+func TestMainPackagesSkipsIgnoredDirectories(t *testing.T) {
+	dir := t.TempDir()
+	for _, file := range []string{"main.go", "api/api.go", "web/web.go", "api/node_modules/pkg/fixture.go"} {
+		writeTestFile(t, dir, file, "package x\n")
+	}
+
+	pkgs, err := mainPackages("example.com/app", dir, []string{"./web", "node_modules"})
+	require.NoError(t, err)
+
+	var importPaths []string
+	for _, pkg := range pkgs {
+		importPaths = append(importPaths, pkg.importPath)
+	}
+	assert.ElementsMatch(t, []string{"example.com/app", "example.com/app/api"}, importPaths)
+}
+
+func TestClosureRecordsPackageFeatures(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "app/main.go", `package main
+
+import (
+	_ "example.com/dep/assets"
+	_ "example.com/dep/native"
+	_ "example.com/dep/plain"
+)
+`)
+	writeTestFile(t, dir, "dep/assets/assets.go", `package assets
+
+import "embed"
+
+//go:embed *.txt
+var FS embed.FS
+`)
+	writeTestFile(t, dir, "dep/assets/hello.txt", "hello\n")
+	writeTestFile(t, dir, "dep/native/native.go", `package native
+
+import "C"
+`)
+	writeTestFile(t, dir, "dep/plain/plain.go", "package plain\n")
+
+	idx := &packageIndex{
+		mains:   map[string]string{"example.com/app": filepath.Join(dir, "app")},
+		modules: map[string]string{"example.com/dep": filepath.Join(dir, "dep")},
+	}
+
+	pkgs, err := idx.closure(runtime.GOOS, runtime.GOARCH)
+	require.NoError(t, err)
+
+	uses := pkgs["example.com/dep"]
+	assert.Equal(t, featureEmbed, uses["example.com/dep/assets"].features)
+	assert.Zero(t, uses["example.com/dep/plain"].features)
+	assert.Equal(t, featureCgo, uses["example.com/dep/native"].features)
+}
+
+func TestClosureRecordsCgoForOtherPlatforms(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "app/main.go", "package main\n\nimport _ \"example.com/dep/native\"\n")
+	writeTestFile(t, dir, "dep/native/native.go", "package native\n\nimport \"C\"\n")
+
+	idx := &packageIndex{
+		mains:   map[string]string{"example.com/app": filepath.Join(dir, "app")},
+		modules: map[string]string{"example.com/dep": filepath.Join(dir, "dep")},
+	}
+
+	goos := "windows"
+	if runtime.GOOS == goos {
+		goos = "linux"
+	}
+	pkgs, err := idx.closure(goos, "arm64")
+	require.NoError(t, err)
+	assert.Equal(t, featureCgo, pkgs["example.com/dep"]["example.com/dep/native"].features)
+}
@@ -0,0 +1,135 @@
+package resolve
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ModCache is a module cache isolated from the user's shared GOMODCACHE.
+// Resolving against a fresh cache guarantees that everything hashed into a
+// manifest comes from a verified download, rather than from a cache entry
+// that may have been corrupted or edited by hand.
+type ModCache struct {
+	Dir       string
+	temporary bool
+}
+
+// NewTempModCache creates an empty module cache in a temporary directory. It
+// is removed by Close.
+func NewTempModCache() (*ModCache, error) {
+	dir, err := os.MkdirTemp("", "govendor-modcache-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary module cache: %w", err)
+	}
+	return &ModCache{Dir: dir, temporary: true}, nil
+}
+
+// OpenModCache uses dir as the module cache, creating it if needed, and
+// fails up front if it cannot be written to. Unlike a temporary cache, it is
+// left in place by Close so it can be reused.
+func OpenModCache(dir string) (*ModCache, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(abs, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create module cache: %w", err)
+	}
+
+	probe, err := os.CreateTemp(abs, ".govendor-probe-")
+	if err != nil {
+		return nil, fmt.Errorf("module cache %s is not writable: %w", abs, err)
+	}
+	probe.Close()
+	if err := os.Remove(probe.Name()); err != nil {
+		return nil, fmt.Errorf("module cache %s is not writable: %w", abs, err)
+	}
+	return &ModCache{Dir: abs}, nil
+}
+
+// Validate checks that the go commands run through exec keep a reused
+// module cache read-only. With -modcacherw in GOFLAGS, extracted modules
+// stay writable, so a later run could hash sources edited in place. A
+// temporary cache is never reused and always passes.
+func (c *ModCache) Validate(ctx context.Context, exec Executor) error {
+	if c.temporary {
+		return nil
+	}
+
+	out, err := exec.Run(ctx, []string{"go", "env", "GOFLAGS"}, ".", nil)
+	if err != nil {
+		return fmt.Errorf("failed to read GOFLAGS: %w", err)
+	}
+	for _, flag := range strings.Fields(out) {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(flag, "-"), "=")
+		if name != "modcacherw" {
+			continue
+		}
+		if on, err := strconv.ParseBool(value); !hasValue || (err == nil && on) {
+			return fmt.Errorf("GOFLAGS sets -modcacherw, which leaves modules in the reused module cache %s writable", c.Dir)
+		}
+	}
+	return nil
+}
+
+// Wrap decorates exec so every command it runs uses this module cache.
+//
+//nolint:ireturn
+func (c *ModCache) Wrap(exec Executor) Executor {
+	return modCacheExecutor{exec: exec, cache: c}
+}
+
+// Seal removes write permission from every file and directory of a module
+// extracted into the cache, matching what the Go command does unless
+// -modcacherw is set. It refuses paths outside of the cache.
+func (c *ModCache) Seal(moduleDir string) error {
+	rel, err := filepath.Rel(c.Dir, moduleDir)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return fmt.Errorf("module directory %s is outside the isolated module cache %s", moduleDir, c.Dir)
+	}
+
+	return filepath.WalkDir(moduleDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return os.Chmod(path, info.Mode().Perm()&^0o222)
+	})
+}
+
+// Close removes a temporary module cache. The Go command extracts modules
+// read-only, so write permission is restored before removal.
+func (c *ModCache) Close() error {
+	if !c.temporary {
+		return nil
+	}
+
+	_ = filepath.WalkDir(c.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() {
+			_ = os.Chmod(path, 0o755)
+		}
+		return nil
+	})
+	return os.RemoveAll(c.Dir)
+}
+
+type modCacheExecutor struct {
+	exec  Executor
+	cache *ModCache
+}
+
+func (e modCacheExecutor) Run(ctx context.Context, args []string, dir string, env []string) (string, error) {
+	return e.exec.Run(ctx, args, dir, append(slices.Clip(env), "GOMODCACHE="+e.cache.Dir))
+}
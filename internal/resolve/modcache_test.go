@@ -0,0 +1,135 @@
+package resolve
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// envRecorder captures the environment passed to the most recent command.
+type envRecorder struct {
+	env []string
+}
+
+func (e *envRecorder) Run(_ context.Context, _ []string, _ string, env []string) (string, error) {
+	e.env = env
+	return "", nil
+}
+
+func TestModCacheWrapSetsGOMODCACHE(t *testing.T) {
+	cache, err := OpenModCache(t.TempDir())
+	require.NoError(t, err)
+
+	rec := &envRecorder{}
+	_, err = cache.Wrap(rec).Run(context.Background(), []string{"go", "mod", "download"}, ".", []string{"GOWORK=off"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"GOWORK=off", "GOMODCACHE=" + cache.Dir}, rec.env)
+}
+
+func TestModCacheSealMakesModuleReadOnly(t *testing.T) {
+	cache, err := NewTempModCache()
+	require.NoError(t, err)
+	t.Cleanup(func() { cache.Close() })
+
+	modDir := filepath.Join(cache.Dir, "example.com", "lib@v1.0.0")
+	writeTestFile(t, modDir, "go.mod", "module example.com/lib\n")
+
+	require.NoError(t, cache.Seal(modDir))
+
+	info, err := os.Stat(filepath.Join(modDir, "go.mod"))
+	require.NoError(t, err)
+	assert.Zero(t, info.Mode().Perm()&0o222)
+
+	info, err = os.Stat(modDir)
+	require.NoError(t, err)
+	assert.Zero(t, info.Mode().Perm()&0o222)
+}
+
+func TestModCacheSealRejectsPathsOutsideCache(t *testing.T) {
+	cache, err := OpenModCache(t.TempDir())
+	require.NoError(t, err)
+
+	err = cache.Seal(t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "outside the isolated module cache")
+}
+
+func TestModCacheCloseRemovesTemporaryCache(t *testing.T) {
+	cache, err := NewTempModCache()
+	require.NoError(t, err)
+
+	modDir := filepath.Join(cache.Dir, "example.com", "lib@v1.0.0")
+	writeTestFile(t, modDir, "lib.go", "package lib\n")
+	require.NoError(t, cache.Seal(modDir))
+
+	require.NoError(t, cache.Close())
+	assert.NoDirExists(t, cache.Dir)
+}
+
+func TestModCacheCloseKeepsExplicitCache(t *testing.T) {
+	cache, err := OpenModCache(filepath.Join(t.TempDir(), "modcache"))
+	require.NoError(t, err)
+
+	require.NoError(t, cache.Close())
+	assert.DirExists(t, cache.Dir)
+}
+
+func TestOpenModCacheRejectsUnwritableDir(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can write to read-only directories")
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, os.Chmod(dir, 0o555))
+	t.Cleanup(func() { _ = os.Chmod(dir, 0o755) })
+
+	_, err := OpenModCache(dir)
+	require.ErrorContains(t, err, "is not writable")
+}
+
+func TestOpenModCacheRejectsFile(t *testing.T) {
+	path := writeTestFile(t, t.TempDir(), "modcache", "")
+
+	_, err := OpenModCache(path)
+	require.ErrorContains(t, err, "failed to create module cache")
+}
+
+func TestModCacheValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		goflags string
+		wantErr bool
+	}{
+		{name: "Unset", goflags: "\n"},
+		{name: "OtherFlags", goflags: "-mod=mod -trimpath\n"},
+		{name: "ModCacheRW", goflags: "-mod=mod -modcacherw\n", wantErr: true},
+		{name: "ModCacheRWTrue", goflags: "--modcacherw=true\n", wantErr: true},
+		{name: "ModCacheRWFalse", goflags: "-modcacherw=false\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache, err := OpenModCache(t.TempDir())
+			require.NoError(t, err)
+
+			err = cache.Validate(context.Background(), &fakeExecutor{responses: map[string]string{"go env GOFLAGS": tt.goflags}})
+			if tt.wantErr {
+				require.ErrorContains(t, err, "GOFLAGS sets -modcacherw")
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestModCacheValidateSkipsTemporaryCache(t *testing.T) {
+	cache, err := NewTempModCache()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = cache.Close() })
+
+	require.NoError(t, cache.Validate(context.Background(), &fakeExecutor{}))
+}
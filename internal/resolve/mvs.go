@@ -0,0 +1,439 @@
+package resolve
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/version"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/purpleclay/conker/pool"
+	"github.com/purpleclay/go-overlay/internal/mod"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+	"golang.org/x/mod/sumdb/dirhash"
+)
+
+// mainModules holds the main modules of a build, either a single module or
+// every member of a workspace, together with the directives that shape how
+// the module graph is loaded from them.
+type mainModules struct {
+	dirs     map[string]string
+	roots    []module.Version
+	download []string
+	all      bool
+	pruned   bool
+	replace  map[module.Version]module.Version
+	exclude  map[module.Version]bool
+	tools    []string
+	ignores  map[string][]string
+	sums     GoSums
+}
+
+func newMainModules() *mainModules {
+	return &mainModules{
+		dirs:    make(map[string]string),
+		replace: make(map[module.Version]module.Version),
+		exclude: make(map[module.Version]bool),
+		ignores: make(map[string][]string),
+	}
+}
+
+// loadMainModule reads the directives of a single main module.
+func loadMainModule(goMod *mod.GoModFile) (*mainModules, error) {
+	f, err := readModFile(goMod.ModFile())
+	if err != nil {
+		return nil, err
+	}
+
+	mains := newMainModules()
+	mains.add(goMod.Dir, f)
+	mains.pruned = isPruned(f.Go)
+
+	if mains.sums, err = ReadGoSums(goMod.SumFile()); err != nil {
+		return nil, err
+	}
+	return mains, nil
+}
+
+// loadMainWorkspace reads the directives of every workspace member, with
+// go.work replace directives taking precedence over those of members. In
+// workspace mode the go command downloads the whole build list.
+func loadMainWorkspace(goWork *mod.GoWorkFile) (*mainModules, error) {
+	mains := newMainModules()
+	mains.pruned = true
+	mains.all = true
+
+	sumFiles := []string{filepath.Join(goWork.Dir, "go.work.sum")}
+	for _, modDir := range goWork.Modules {
+		dir := filepath.Join(goWork.Dir, modDir)
+		f, err := readModFile(filepath.Join(dir, mod.GoModFilename))
+		if err != nil {
+			return nil, err
+		}
+		mains.add(dir, f)
+		sumFiles = append(sumFiles, filepath.Join(dir, "go.sum"))
+	}
+
+	workPath := filepath.Join(goWork.Dir, mod.GoWorkFilename)
+	if data, err := os.ReadFile(workPath); err == nil {
+		wf, err := modfile.ParseWork(workPath, data, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse go.work: %w", err)
+		}
+		for _, r := range wf.Replace {
+			mains.replace[r.Old] = replacementTarget(goWork.Dir, r.New)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read go.work: %w", err)
+	}
+
+	var err error
+	if mains.sums, err = ReadGoSums(sumFiles...); err != nil {
+		return nil, err
+	}
+	return mains, nil
+}
+
+func (m *mainModules) add(dir string, f *modfile.File) {
+	m.dirs[f.Module.Mod.Path] = dir
+	for _, r := range f.Require {
+		m.roots = append(m.roots, r.Mod)
+		m.download = append(m.download, r.Mod.Path)
+	}
+	// Modules before go 1.17 do not list every dependency providing a
+	// package, so the go command downloads the whole build list for them.
+	if !isPruned(f.Go) {
+		m.all = true
+	}
+	for _, r := range f.Replace {
+		m.replace[r.Old] = replacementTarget(dir, r.New)
+	}
+	for _, e := range f.Exclude {
+		m.exclude[e.Mod] = true
+	}
+	for _, t := range f.Tool {
+		m.tools = append(m.tools, t.Path)
+	}
+	for _, i := range f.Ignore {
+		m.ignores[f.Module.Mod.Path] = append(m.ignores[f.Module.Mod.Path], i.Path)
+	}
+}
+
+func (m *mainModules) isMain(path string) bool {
+	_, ok := m.dirs[path]
+	return ok
+}
+
+// replacement returns the module that provides m after applying replace
+// directives. A version-specific replacement wins over a wildcard one. Local
+// replacements are returned with an absolute directory as the path and an
+// empty version.
+func (m *mainModules) replacement(v module.Version) module.Version {
+	if r, ok := m.replace[v]; ok {
+		return r
+	}
+	if r, ok := m.replace[module.Version{Path: v.Path}]; ok {
+		return r
+	}
+	return v
+}
+
+func replacementTarget(dir string, target module.Version) module.Version {
+	if target.Version != "" {
+		return target
+	}
+	if !filepath.IsAbs(target.Path) {
+		target.Path = filepath.Join(dir, target.Path)
+	}
+	return target
+}
+
+// isPruned reports whether a module's requirements are pruned, which is the
+// case from go 1.17 onwards. A missing go directive implies go 1.16.
+func isPruned(goStmt *modfile.Go) bool {
+	return goStmt != nil && version.Compare("go"+goStmt.Version, "go1.17") >= 0
+}
+
+func readModFile(path string) (*modfile.File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	f, err := modfile.Parse(path, data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if f.Module == nil {
+		return nil, fmt.Errorf("%s is missing a module directive", path)
+	}
+	return f, nil
+}
+
+// GoSums holds go.sum hashes keyed by "path version", where the version of
+// a go.mod hash carries a "/go.mod" suffix.
+type GoSums map[string]string
+
+// ReadGoSums merges the go.sum files at paths, skipping any that do not
+// exist.
+func ReadGoSums(paths ...string) (GoSums, error) {
+	sums := make(GoSums)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) == 3 {
+				sums[fields[0]+" "+fields[1]] = fields[2]
+			}
+		}
+	}
+	return sums, nil
+}
+
+// Lookup returns the go.sum hash of a module's source.
+func (s GoSums) Lookup(path, version string) (string, bool) {
+	hash, ok := s[path+" "+version]
+	return hash, ok
+}
+
+// verify checks a computed hash against go.sum, using the same wording as
+// the go command so failures are classified alike.
+func (s GoSums) verify(path, version, hash string) error {
+	want, ok := s[path+" "+version]
+	if !ok {
+		return fmt.Errorf("%s@%s: missing go.sum entry", path, version)
+	}
+	if want != hash {
+		return fmt.Errorf("verifying %s@%s: checksum mismatch\n\tdownloaded: %s\n\tgo.sum:     %s", path, version, hash, want)
+	}
+	return nil
+}
+
+// modSummary is the part of a dependency's go.mod needed to load the
+// module graph.
+type modSummary struct {
+	pruned  bool
+	require []module.Version
+}
+
+// graphLoader loads the module graph of the main modules from go.mod files
+// served by a Fetcher, following the same rules as the go command.
+type graphLoader struct {
+	fetch Fetcher
+	mains *mainModules
+
+	mu        sync.Mutex
+	summaries map[module.Version]*modSummary
+	goMods    map[module.Version][]byte
+}
+
+func newGraphLoader(fetch Fetcher, mains *mainModules) *graphLoader {
+	return &graphLoader{
+		fetch:     fetch,
+		mains:     mains,
+		summaries: make(map[module.Version]*modSummary),
+		goMods:    make(map[module.Version][]byte),
+	}
+}
+
+// buildList loads the module graph and applies minimal version selection,
+// returning the selected version of every module other than the main
+// modules.
+//
+// Graph pruning matches the go command: a module at go 1.17 or later only
+// contributes its direct requirements, while older modules, and everything
+// reachable from them, are loaded transitively.
+//
+// A requirement on a version excluded by a main module is ignored, as the go
+// command has done since go 1.16. Earlier releases upgraded it to the next
+// version that is not excluded instead.
+func (l *graphLoader) buildList(ctx context.Context) (map[string]string, error) {
+	type queued struct {
+		mod      module.Version
+		unpruned bool
+	}
+
+	selected := make(map[string]string)
+	selectVersion := func(v module.Version) {
+		if !l.mains.isMain(v.Path) && semver.Compare(v.Version, selected[v.Path]) > 0 {
+			selected[v.Path] = v.Version
+		}
+	}
+
+	var (
+		queue    []queued
+		loaded   = make(map[module.Version]bool)
+		expanded = make(map[module.Version]bool)
+	)
+	enqueue := func(v module.Version, unpruned bool) {
+		if l.mains.isMain(v.Path) {
+			return
+		}
+		if unpruned {
+			if expanded[v] {
+				return
+			}
+			expanded[v] = true
+		} else if loaded[v] {
+			return
+		}
+		loaded[v] = true
+		queue = append(queue, queued{mod: v, unpruned: unpruned})
+	}
+
+	for _, root := range l.mains.roots {
+		if l.mains.exclude[root] {
+			continue
+		}
+		selectVersion(root)
+		enqueue(root, !l.mains.pruned)
+	}
+
+	type loadedSummary struct {
+		queued
+		summary *modSummary
+	}
+
+	for len(queue) > 0 {
+		batch := queue
+		queue = nil
+
+		p := pool.NewWithResults[loadedSummary]().WithMaxGoroutines(16).WithContext(ctx)
+		for _, q := range batch {
+			p.Go(func(ctx context.Context) (loadedSummary, error) {
+				s, err := l.summary(ctx, q.mod)
+				return loadedSummary{queued: q, summary: s}, err
+			})
+		}
+
+		results, err := p.Wait()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, res := range results {
+			for _, req := range res.summary.require {
+				selectVersion(req)
+				if res.unpruned || !res.summary.pruned {
+					enqueue(req, true)
+				}
+			}
+		}
+	}
+
+	return selected, nil
+}
+
+func (l *graphLoader) summary(ctx context.Context, v module.Version) (*modSummary, error) {
+	target := l.mains.replacement(v)
+
+	l.mu.Lock()
+	s, ok := l.summaries[target]
+	l.mu.Unlock()
+	if ok {
+		return s, nil
+	}
+
+	data, err := l.goMod(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := modfile.ParseLax(target.Path+"@"+target.Version+"/go.mod", data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse go.mod of %s@%s: %w", v.Path, v.Version, err)
+	}
+
+	s = &modSummary{pruned: isPruned(f.Go)}
+	for _, r := range f.Require {
+		if !l.mains.exclude[r.Mod] {
+			s.require = append(s.require, r.Mod)
+		}
+	}
+
+	l.mu.Lock()
+	l.summaries[target] = s
+	l.mu.Unlock()
+	return s, nil
+}
+
+// goMod reads the go.mod of a module, either from a local replacement
+// directory or from the Fetcher, verifying fetched files against go.sum.
+// Fetched files are kept for reuse when the module is later extracted.
+func (l *graphLoader) goMod(ctx context.Context, target module.Version) ([]byte, error) {
+	l.mu.Lock()
+	data, ok := l.goMods[target]
+	l.mu.Unlock()
+	if ok {
+		return data, nil
+	}
+
+	if target.Version == "" {
+		data, err := os.ReadFile(filepath.Join(target.Path, mod.GoModFilename))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read go.mod of local module %s: %w", target.Path, err)
+		}
+		return data, nil
+	}
+
+	data, err := l.fetch.GoMod(ctx, target.Path, target.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := dirhash.Hash1([]string{mod.GoModFilename}, func(string) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := l.mains.sums.verify(target.Path, target.Version+"/go.mod", hash); err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	l.goMods[target] = data
+	l.mu.Unlock()
+	return data, nil
+}
+
+// downloadSet returns the modules fetched by `go mod download` without
+// arguments: the direct requirements of the main modules, or the whole
+// build list in workspace mode or if the main module predates graph
+// pruning. Each is returned at its selected version, before replacements
+// are applied.
+func (m *mainModules) downloadSet(selected map[string]string) []module.Version {
+	paths := m.download
+	if m.all {
+		paths = paths[:0:0]
+		for path := range selected {
+			paths = append(paths, path)
+		}
+	}
+
+	seen := make(map[string]bool, len(paths))
+	var set []module.Version
+	for _, path := range paths {
+		if seen[path] || m.isMain(path) || selected[path] == "" {
+			continue
+		}
+		seen[path] = true
+		set = append(set, module.Version{Path: path, Version: selected[path]})
+	}
+	return set
+}
@@ -0,0 +1,230 @@
+package resolve
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/purpleclay/go-overlay/internal/mod"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/sumdb/dirhash"
+)
+
+// fakeFetcher serves go.mod files keyed by "path@version". Zips are not
+// needed to load a module graph.
+type fakeFetcher struct {
+	goMods map[string]string
+}
+
+func (f *fakeFetcher) GoMod(_ context.Context, path, version string) ([]byte, error) {
+	if data, ok := f.goMods[path+"@"+version]; ok {
+		return []byte(data), nil
+	}
+	return nil, fmt.Errorf("reading %s/@v/%s.mod: 404 Not Found", path, version)
+}
+
+func (f *fakeFetcher) Zip(_ context.Context, path, version string) (io.ReadCloser, error) {
+	return nil, fmt.Errorf("unexpected zip request for %s@%s", path, version)
+}
+
+// goSum returns a go.sum covering every go.mod served by the fetcher.
+func (f *fakeFetcher) goSum(t *testing.T) string {
+	t.Helper()
+	var lines []string
+	for key, data := range f.goMods {
+		path, version, _ := strings.Cut(key, "@")
+		hash, err := dirhash.Hash1([]string{"go.mod"}, func(string) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader([]byte(data))), nil
+		})
+		require.NoError(t, err)
+		lines = append(lines, fmt.Sprintf("%s %s/go.mod %s", path, version, hash))
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func buildList(t *testing.T, goMod string, fetch *fakeFetcher, goSum string) (map[string]string, error) {
+	t.Helper()
+	dir := t.TempDir()
+	writeTestFile(t, dir, "go.mod", goMod)
+	writeTestFile(t, dir, "go.sum", goSum)
+
+	parsed, err := mod.ParseGoModFile(filepath.Join(dir, "go.mod"))
+	require.NoError(t, err)
+
+	mains, err := loadMainModule(parsed)
+	require.NoError(t, err)
+
+	return newGraphLoader(fetch, mains).buildList(context.Background())
+}
+
+func TestBuildListSelectsHighestRequiredVersion(t *testing.T) {
+	fetch := &fakeFetcher{goMods: map[string]string{
+		"example.com/a@v1.0.0": "module example.com/a\n\ngo 1.21\n",
+		"example.com/b@v1.0.0": "module example.com/b\n\ngo 1.21\n\nrequire example.com/a v1.2.0\n",
+	}}
+
+	selected, err := buildList(t, "module example.com/main\n\ngo 1.22\n\nrequire (\n\texample.com/a v1.0.0\n\texample.com/b v1.0.0\n)\n", fetch, fetch.goSum(t))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"example.com/a": "v1.2.0",
+		"example.com/b": "v1.0.0",
+	}, selected)
+}
+
+func TestBuildListPrunesDependenciesAtGo117(t *testing.T) {
+	fetch := &fakeFetcher{goMods: map[string]string{
+		"example.com/b@v1.0.0": "module example.com/b\n\ngo 1.21\n\nrequire example.com/c v1.0.0\n",
+	}}
+
+	selected, err := buildList(t, "module example.com/main\n\ngo 1.22\n\nrequire example.com/b v1.0.0\n", fetch, fetch.goSum(t))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"example.com/b": "v1.0.0",
+		"example.com/c": "v1.0.0",
+	}, selected)
+}
+
+func TestBuildListLoadsUnprunedDependenciesTransitively(t *testing.T) {
+	fetch := &fakeFetcher{goMods: map[string]string{
+		"example.com/b@v1.0.0": "module example.com/b\n\ngo 1.16\n\nrequire example.com/c v1.0.0\n",
+		"example.com/c@v1.0.0": "module example.com/c\n\ngo 1.21\n\nrequire example.com/d v1.0.0\n",
+		"example.com/d@v1.0.0": "module example.com/d\n\ngo 1.21\n\nrequire example.com/e v1.0.0\n",
+		"example.com/e@v1.0.0": "module example.com/e\n",
+	}}
+
+	selected, err := buildList(t, "module example.com/main\n\ngo 1.22\n\nrequire example.com/b v1.0.0\n", fetch, fetch.goSum(t))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"example.com/b": "v1.0.0",
+		"example.com/c": "v1.0.0",
+		"example.com/d": "v1.0.0",
+		"example.com/e": "v1.0.0",
+	}, selected)
+}
+
+func TestBuildListIgnoresExcludedVersions(t *testing.T) {
+	fetch := &fakeFetcher{goMods: map[string]string{
+		"example.com/a@v1.0.0": "module example.com/a\n\ngo 1.21\n",
+		"example.com/b@v1.0.0": "module example.com/b\n\ngo 1.21\n\nrequire example.com/a v1.2.0\n",
+	}}
+
+	goMod := "module example.com/main\n\ngo 1.22\n\nrequire (\n\texample.com/a v1.0.0\n\texample.com/b v1.0.0\n)\n\nexclude example.com/a v1.2.0\n"
+	selected, err := buildList(t, goMod, fetch, fetch.goSum(t))
+	require.NoError(t, err)
+	assert.Equal(t, "v1.0.0", selected["example.com/a"])
+}
+
+// goBuildList runs `go list -m all` for goMod against a file:// proxy serving
+// the go.mod files of fetch, returning the selected version of every module
+// other than the main module.
+func goBuildList(t *testing.T, goMod string, fetch *fakeFetcher) map[string]string {
+	t.Helper()
+	proxy := t.TempDir()
+	versions := make(map[string][]string)
+	for key, data := range fetch.goMods {
+		path, version, _ := strings.Cut(key, "@")
+		writeTestFile(t, proxy, path+"/@v/"+version+".mod", data)
+		writeTestFile(t, proxy, path+"/@v/"+version+".info", fmt.Sprintf(`{"Version":%q}`, version))
+		versions[path] = append(versions[path], version)
+	}
+	for path, list := range versions {
+		writeTestFile(t, proxy, path+"/@v/list", strings.Join(list, "\n")+"\n")
+	}
+
+	dir := t.TempDir()
+	writeTestFile(t, dir, "go.mod", goMod)
+	writeTestFile(t, dir, "go.sum", fetch.goSum(t))
+
+	env := []string{
+		"GOPROXY=file://" + filepath.ToSlash(proxy),
+		"GOMODCACHE=" + t.TempDir(),
+		"GOFLAGS=-mod=mod -modcacherw",
+		"GONOSUMDB=*",
+		"GOSUMDB=off",
+		"GOTOOLCHAIN=local",
+		"GOWORK=off",
+	}
+	out, err := OSExecutor{}.Run(context.Background(), []string{"go", "list", "-m", "-f", "{{if not .Main}}{{.Path}} {{.Version}}{{end}}", "all"}, dir, env)
+	require.NoError(t, err)
+
+	selected := make(map[string]string)
+	for line := range strings.SplitSeq(strings.TrimSpace(string(out)), "\n") {
+		if path, version, ok := strings.Cut(line, " "); ok {
+			selected[path] = version
+		}
+	}
+	return selected
+}
+
+// TestBuildListMatchesGoCommandForExcludedVersions compares the build list
+// with the go command's when requirements name excluded versions. Since go
+// 1.16 the go command ignores such a requirement rather than upgrading it
+// to the next version that is not excluded.
+func TestBuildListMatchesGoCommandForExcludedVersions(t *testing.T) {
+	fetch := &fakeFetcher{goMods: map[string]string{
+		"example.com/a@v1.0.0": "module example.com/a\n\ngo 1.21\n",
+		"example.com/a@v1.1.0": "module example.com/a\n\ngo 1.21\n",
+		"example.com/a@v1.2.0": "module example.com/a\n\ngo 1.21\n",
+		"example.com/b@v1.0.0": "module example.com/b\n\ngo 1.21\n\nrequire example.com/a v1.1.0\n",
+		"example.com/c@v1.0.0": "module example.com/c\n\ngo 1.21\n\nrequire example.com/a v1.0.0\n",
+	}}
+
+	tests := []struct {
+		name  string
+		goMod string
+	}{
+		{
+			name:  "ExcludedMainRequirement",
+			goMod: "module example.com/main\n\ngo 1.22\n\nrequire example.com/a v1.1.0\n\nexclude example.com/a v1.1.0\n",
+		},
+		{
+			name:  "ExcludedDependencyRequirement",
+			goMod: "module example.com/main\n\ngo 1.22\n\nrequire example.com/b v1.0.0\n\nexclude example.com/a v1.1.0\n",
+		},
+		{
+			name:  "ExcludedRequirementWithLowerVersionRequired",
+			goMod: "module example.com/main\n\ngo 1.22\n\nrequire (\n\texample.com/b v1.0.0\n\texample.com/c v1.0.0\n)\n\nexclude example.com/a v1.1.0\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, err := buildList(t, tt.goMod, fetch, fetch.goSum(t))
+			require.NoError(t, err)
+			assert.Equal(t, goBuildList(t, tt.goMod, fetch), selected)
+		})
+	}
+}
+
+func TestBuildListAppliesReplacements(t *testing.T) {
+	fetch := &fakeFetcher{goMods: map[string]string{
+		"example.com/fork@v1.5.0": "module example.com/fork\n\ngo 1.21\n\nrequire example.com/c v1.1.0\n",
+	}}
+
+	goMod := "module example.com/main\n\ngo 1.22\n\nrequire example.com/b v1.0.0\n\nreplace example.com/b => example.com/fork v1.5.0\n"
+	selected, err := buildList(t, goMod, fetch, fetch.goSum(t))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"example.com/b": "v1.0.0",
+		"example.com/c": "v1.1.0",
+	}, selected)
+}
+
+func TestBuildListRequiresGoSumEntries(t *testing.T) {
+	fetch := &fakeFetcher{goMods: map[string]string{
+		"example.com/b@v1.0.0": "module example.com/b\n\ngo 1.21\n",
+	}}
+
+	_, err := buildList(t, "module example.com/main\n\ngo 1.22\n\nrequire example.com/b v1.0.0\n", fetch, "")
+	require.Error(t, err)
+
+	var classified *ClassifiedError
+	require.ErrorAs(t, Classify(err), &classified)
+	assert.Equal(t, ClassMissingGoSum, classified.Class)
+}
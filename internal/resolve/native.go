@@ -0,0 +1,370 @@
+package resolve
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/purpleclay/conker/pool"
+	"github.com/purpleclay/go-overlay/internal/mod"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/sumdb/dirhash"
+	modzip "golang.org/x/mod/zip"
+)
+
+// Fetcher retrieves module go.mod files and source archives, typically from
+// a module proxy.
+type Fetcher interface {
+	GoMod(ctx context.Context, path, version string) ([]byte, error)
+	Zip(ctx context.Context, path, version string) (io.ReadCloser, error)
+}
+
+// NativeResolver resolves Go module dependencies without the go command. The
+// module graph is built with minimal version selection over go.mod files
+// from a Fetcher, modules are extracted exactly as the go command extracts
+// them into its module cache, and package lists are computed with go/build
+// for each platform. It produces the same module list as Resolver, so
+// manifests can be generated where no matching Go toolchain is installed.
+// Git is still needed to hash locally replaced modules.
+type NativeResolver struct {
+	fetch Fetcher
+	r     *Resolver
+}
+
+// NewNative creates a NativeResolver that fetches modules with fetch. The
+// executor is only used to run git. WithModCache selects where modules are
+// extracted; by default a temporary directory is used for each resolution.
+func NewNative(fetch Fetcher, exec Executor, opts ...Option) *NativeResolver {
+	return &NativeResolver{fetch: fetch, r: New(exec, opts...)}
+}
+
+// ValidatePlatforms checks that all given platform strings are of the form
+// GOOS/GOARCH. Without a toolchain to ask, support for a platform is not
+// verified.
+func (n *NativeResolver) ValidatePlatforms(_ context.Context, platforms []string) error {
+	var invalid []string
+	for _, p := range platforms {
+		if goos, goarch, ok := strings.Cut(p, "/"); !ok || goos == "" || goarch == "" {
+			invalid = append(invalid, p)
+		}
+	}
+
+	if len(invalid) > 0 {
+		return fmt.Errorf("unsupported platform(s): %s", strings.Join(invalid, ", "))
+	}
+	return nil
+}
+
+// ResolveModule resolves all dependencies for a single Go module. Failures
+// matching a known class are returned as a *ClassifiedError.
+func (n *NativeResolver) ResolveModule(ctx context.Context, goMod *mod.GoModFile, platforms []string, patterns ...string) ([]mod.ModuleConfig, error) {
+	modules, err := n.resolveModule(ctx, goMod, platforms, patterns)
+	return modules, Classify(err)
+}
+
+func (n *NativeResolver) resolveModule(ctx context.Context, goMod *mod.GoModFile, platforms, patterns []string) ([]mod.ModuleConfig, error) {
+	defer n.r.trace.Start("resolve", goMod.ModulePath, map[string]any{"dir": goMod.Dir, "resolver": "native"})()
+
+	mains, err := loadMainModule(goMod)
+	if err != nil {
+		return nil, err
+	}
+
+	cache, closeCache, err := n.modCache()
+	if err != nil {
+		return nil, err
+	}
+	defer closeCache()
+
+	selection := packageSelection{
+		dir:       goMod.Dir,
+		patterns:  patterns,
+		toolsOnly: goMod.IsAlternate() && len(patterns) == 0,
+	}
+	downloads, pkgsByMod, err := n.load(ctx, cache, mains, false, platforms, selection)
+	if err != nil {
+		return nil, err
+	}
+
+	return n.r.assembleModule(ctx, goMod, downloads, pkgsByMod)
+}
+
+// ResolveWorkspace resolves dependencies across all modules in a Go
+// workspace, applying minimal version selection across every member.
+// Failures matching a known class are returned as a *ClassifiedError.
+func (n *NativeResolver) ResolveWorkspace(ctx context.Context, goWork *mod.GoWorkFile, platforms []string, patterns ...string) ([]mod.ModuleConfig, error) {
+	modules, err := n.resolveWorkspace(ctx, goWork, platforms, patterns)
+	return modules, Classify(err)
+}
+
+func (n *NativeResolver) resolveWorkspace(ctx context.Context, goWork *mod.GoWorkFile, platforms, patterns []string) ([]mod.ModuleConfig, error) {
+	defer n.r.trace.Start("resolve", mod.GoWorkFilename, map[string]any{"dir": goWork.Dir, "resolver": "native"})()
+
+	mains, err := loadMainWorkspace(goWork)
+	if err != nil {
+		return nil, err
+	}
+
+	memberGoMods, err := parseMemberGoMods(goWork)
+	if err != nil {
+		return nil, err
+	}
+
+	cache, closeCache, err := n.modCache()
+	if err != nil {
+		return nil, err
+	}
+	defer closeCache()
+
+	selection := packageSelection{dir: goWork.Dir, patterns: patterns}
+	downloads, pkgsByMod, err := n.load(ctx, cache, mains, true, platforms, selection)
+	if err != nil {
+		return nil, err
+	}
+
+	return n.r.assembleWorkspace(ctx, goWork, memberGoMods, downloads, pkgsByMod)
+}
+
+// load selects the build list of the main modules, extracts the modules
+// `go mod download` would fetch into cache, and computes their package
+// lists for every platform, following the imports of the main packages in
+// selection.
+func (n *NativeResolver) load(ctx context.Context, cache *ModCache, mains *mainModules, includeMains bool, platforms []string, selection packageSelection) ([]ModuleDownload, modulePackages, error) {
+	if platforms == nil {
+		platforms = mod.DefaultPlatforms()
+	}
+
+	loader := newGraphLoader(n.fetch, mains)
+
+	endGraph := n.r.span(ctx, "graph", "loading module graph", nil)
+	selected, err := loader.buildList(ctx)
+	endGraph()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	downloads, err := n.extractModules(ctx, cache, loader, selected)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	idx := &packageIndex{
+		mains:        mains.dirs,
+		modules:      make(map[string]string),
+		tools:        mains.tools,
+		ignores:      mains.ignores,
+		selection:    selection,
+		includeMains: includeMains,
+	}
+	for _, dl := range downloads {
+		idx.modules[dl.origin] = dl.Dir
+	}
+	for _, v := range mains.roots {
+		if target := mains.replacement(v); target.Version == "" && !mains.isMain(v.Path) {
+			idx.modules[v.Path] = target.Path
+		}
+	}
+
+	pkgsByMod, err := n.packages(ctx, idx, platforms)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := make([]ModuleDownload, len(downloads))
+	for i, dl := range downloads {
+		result[i] = dl.ModuleDownload
+	}
+	return result, pkgsByMod, nil
+}
+
+// modCache returns the module cache set by WithModCache, or a temporary one
+// removed by the returned function once resolution completes.
+func (n *NativeResolver) modCache() (*ModCache, func(), error) {
+	if n.r.modCache != nil {
+		return n.r.modCache, func() {}, nil
+	}
+
+	cache, err := NewTempModCache()
+	if err != nil {
+		return nil, nil, err
+	}
+	return cache, func() { _ = cache.Close() }, nil
+}
+
+type nativeDownload struct {
+	ModuleDownload
+	origin string
+}
+
+// extractModules fetches and extracts every module in the download set
+// into the module cache, using the go command's layout so an existing
+// GOMODCACHE can be reused. Locally replaced modules are skipped.
+func (n *NativeResolver) extractModules(ctx context.Context, cache *ModCache, loader *graphLoader, selected map[string]string) ([]nativeDownload, error) {
+	defer n.r.span(ctx, "download", "fetching modules", nil)()
+
+	p := pool.NewWithResults[nativeDownload]().WithMaxGoroutines(8).WithContext(ctx)
+	for _, v := range loader.mains.downloadSet(selected) {
+		target := loader.mains.replacement(v)
+		if target.Version == "" {
+			continue
+		}
+
+		p.Go(func(ctx context.Context) (nativeDownload, error) {
+			dl, err := n.extract(ctx, cache.Dir, loader, target)
+			return nativeDownload{ModuleDownload: dl, origin: v.Path}, err
+		})
+	}
+
+	downloads, err := p.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(downloads, func(a, b nativeDownload) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return downloads, nil
+}
+
+func (n *NativeResolver) extract(ctx context.Context, root string, loader *graphLoader, v module.Version) (ModuleDownload, error) {
+	escPath, err := module.EscapePath(v.Path)
+	if err != nil {
+		return ModuleDownload{}, err
+	}
+	escVersion, err := module.EscapeVersion(v.Version)
+	if err != nil {
+		return ModuleDownload{}, err
+	}
+
+	dl := ModuleDownload{
+		Path:    v.Path,
+		Version: v.Version,
+		Dir:     filepath.Join(root, escPath+"@"+escVersion),
+		GoMod:   filepath.Join(root, "cache", "download", escPath, "@v", escVersion+".mod"),
+	}
+
+	if isDir(dl.Dir) && isFile(dl.GoMod) {
+		return dl, nil
+	}
+
+	if !isFile(dl.GoMod) {
+		data, err := loader.goMod(ctx, v)
+		if err != nil {
+			return ModuleDownload{}, err
+		}
+		if err := writeCacheFile(dl.GoMod, data); err != nil {
+			return ModuleDownload{}, err
+		}
+	}
+
+	if isDir(dl.Dir) {
+		return dl, nil
+	}
+
+	zipFile, err := downloadZip(ctx, n.fetch, filepath.Dir(dl.GoMod), v)
+	if err != nil {
+		return ModuleDownload{}, err
+	}
+	defer os.Remove(zipFile)
+
+	hash, err := dirhash.HashZip(zipFile, dirhash.Hash1)
+	if err != nil {
+		return ModuleDownload{}, err
+	}
+	if err := loader.mains.sums.verify(v.Path, v.Version, hash); err != nil {
+		return ModuleDownload{}, err
+	}
+
+	// Extract alongside the final location and rename into place, so an
+	// interrupted extraction never leaves a partial module behind.
+	tmpDir, err := os.MkdirTemp(root, escVersion+".tmp-")
+	if err != nil {
+		return ModuleDownload{}, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	extracted := filepath.Join(tmpDir, "mod")
+	if err := modzip.Unzip(extracted, v, zipFile); err != nil {
+		return ModuleDownload{}, fmt.Errorf("failed to extract %s@%s: %w", v.Path, v.Version, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(dl.Dir), 0o755); err != nil {
+		return ModuleDownload{}, err
+	}
+	if err := os.Rename(extracted, dl.Dir); err != nil && !isDir(dl.Dir) {
+		return ModuleDownload{}, err
+	}
+	return dl, nil
+}
+
+// packages computes the package import closure for every platform and
+// merges the results.
+func (n *NativeResolver) packages(ctx context.Context, idx *packageIndex, platforms []string) (modulePackages, error) {
+	p := pool.NewWithResults[modulePackages]().WithContext(ctx)
+
+	seen := make(map[string]struct{}, len(platforms))
+	for _, plat := range platforms {
+		goos, goarch, ok := strings.Cut(plat, "/")
+		if !ok || goos == "" || goarch == "" {
+			return nil, fmt.Errorf("invalid platform %q: expected GOOS/GOARCH", plat)
+		}
+		if _, dup := seen[plat]; dup {
+			continue
+		}
+		seen[plat] = struct{}{}
+		p.Go(func(ctx context.Context) (modulePackages, error) {
+			defer n.r.span(ctx, "imports", "import closure ("+plat+")", nil)()
+			return idx.closure(goos, goarch)
+		})
+	}
+
+	results, err := p.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	merged := make(modulePackages)
+	for _, result := range results {
+		merged.merge(result)
+	}
+	return merged, nil
+}
+
+// writeCacheFile atomically writes a read-only file into the module cache.
+func writeCacheFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Chmod(0o444); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
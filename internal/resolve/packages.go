@@ -1,28 +1,61 @@
 package resolve
 
 import (
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
 	"sort"
 	"strings"
+
+	"github.com/purpleclay/go-overlay/internal/mod"
 )
 
+// packageFormat is the `go list` template for a single package: the path of
+// its module and its import path, followed by a column naming each feature
+// it uses.
+const packageFormat = `{{.Module.Path}}{{"\t"}}{{.ImportPath}}{{if .CgoFiles}}{{"\t"}}cgo{{end}}{{if .EmbedPatterns}}{{"\t"}}embed{{end}}`
+
+// listFormat returns the `go list` template listing every package outside
+// the standard library, skipping those of the main module at mainPath
+// unless it is empty.
+func listFormat(mainPath string) string {
+	if mainPath == "" {
+		return `{{if not .Standard}}{{if .Module}}` + packageFormat + `{{end}}{{end}}`
+	}
+	return fmt.Sprintf(`{{if not .Standard}}{{if .Module}}{{if ne .Module.Path "%s"}}%s{{end}}{{end}}{{end}}`, mainPath, packageFormat)
+}
+
 // ParsePackagesByModule parses the tab-separated output of `go list` into a
 // map of module path to imported package paths. Lines without a tab are
-// skipped.
+// skipped, as are any columns after the package path.
 func ParsePackagesByModule(out string) map[string][]string {
 	pkgsByMod := make(map[string][]string)
-	for line := range strings.SplitSeq(out, "\n") {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
+	for modPath, columns := range listedPackages(out) {
+		pkgsByMod[modPath] = append(pkgsByMod[modPath], columns[0])
+	}
+	return pkgsByMod
+}
 
-		modPath, pkgPath, ok := strings.Cut(line, "\t")
-		if !ok {
-			continue
+// listedPackages yields the module path of each package in the output of
+// `go list`, along with its import path and the features that follow it.
+func listedPackages(out string) iter.Seq2[string, []string] {
+	return func(yield func(string, []string) bool) {
+		for line := range strings.SplitSeq(out, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+
+			modPath, rest, ok := strings.Cut(line, "\t")
+			if !ok {
+				continue
+			}
+			if !yield(modPath, strings.Split(rest, "\t")) {
+				return
+			}
 		}
-		pkgsByMod[modPath] = append(pkgsByMod[modPath], pkgPath)
 	}
-	return pkgsByMod
 }
 
 // MergePackages combines two package lists, deduplicating and sorting.
@@ -42,3 +75,148 @@ func MergePackages(a, b []string) []string {
 	sort.Strings(result)
 	return result
 }
+
+// scope is the set of uses that need a package: building the main modules,
+// testing them, or building their tools.
+type scope uint8
+
+const (
+	scopeBuild scope = 1 << iota
+	scopeTest
+	scopeTool
+)
+
+// names returns the manifest names of the scopes in s other than the build.
+func (s scope) names() []string {
+	var names []string
+	if s&scopeTest != 0 {
+		names = append(names, mod.ScopeTest)
+	}
+	if s&scopeTool != 0 {
+		names = append(names, mod.ScopeTool)
+	}
+	return names
+}
+
+// feature is the set of language features a package uses that affect how it
+// is built: cgo, which needs a C toolchain, and //go:embed, which rejects
+// symlinked files before Go 1.25.
+type feature uint8
+
+const (
+	featureCgo feature = 1 << iota
+	featureEmbed
+)
+
+// parseFeature returns the feature named in a column of `go list` output.
+func parseFeature(name string) feature {
+	switch name {
+	case mod.FeatureCgo:
+		return featureCgo
+	case mod.FeatureEmbed:
+		return featureEmbed
+	}
+	return 0
+}
+
+// packageUse records the scope in which a package is needed and the
+// features it uses.
+type packageUse struct {
+	scope    scope
+	features feature
+}
+
+// modulePackages maps a module path to the packages it provides, each with
+// the scope in which it is needed and the features it uses.
+type modulePackages map[string]map[string]packageUse
+
+// add records that pkgPath, provided by modPath, is needed in scope s and
+// uses the features f.
+func (mp modulePackages) add(modPath, pkgPath string, s scope, f feature) {
+	pkgs, ok := mp[modPath]
+	if !ok {
+		pkgs = make(map[string]packageUse)
+		mp[modPath] = pkgs
+	}
+	use := pkgs[pkgPath]
+	use.scope |= s
+	use.features |= f
+	pkgs[pkgPath] = use
+}
+
+// addListing records every package in the tab-separated output of `go list`
+// as needed in scope s, along with the features listed after it.
+func (mp modulePackages) addListing(out string, s scope) {
+	for modPath, columns := range listedPackages(out) {
+		var f feature
+		for _, name := range columns[1:] {
+			f |= parseFeature(name)
+		}
+		mp.add(modPath, columns[0], s, f)
+	}
+}
+
+// merge records every package of other in mp, combining their scopes and
+// features. A package uses a feature if it does on any platform.
+func (mp modulePackages) merge(other modulePackages) {
+	for modPath, pkgs := range other {
+		for pkg, use := range pkgs {
+			mp.add(modPath, pkg, use.scope, use.features)
+		}
+	}
+}
+
+// packages returns the sorted packages of a module in any scope.
+func (mp modulePackages) packages(modPath string) []string {
+	if len(mp[modPath]) == 0 {
+		return nil
+	}
+	return slices.Sorted(maps.Keys(mp[modPath]))
+}
+
+// annotate records the packages of a module in cfg, along with the scope of
+// the module when the build does not need it and the features its packages
+// use. Packages outside the build are only listed by scope when they differ
+// from the module as a whole.
+func (mp modulePackages) annotate(cfg *mod.ModuleConfig) {
+	pkgs := mp[cfg.Path]
+	cfg.Packages = mp.packages(cfg.Path)
+
+	var (
+		modScope    scope
+		modFeatures feature
+	)
+	for _, use := range pkgs {
+		modScope |= use.scope
+		modFeatures |= use.features
+	}
+	if modScope&scopeBuild == 0 {
+		cfg.Scope = modScope.names()
+	}
+	cfg.Cgo = modFeatures&featureCgo != 0
+	cfg.Embed = modFeatures&featureEmbed != 0
+
+	uniform := modScope&scopeBuild == 0
+	for _, use := range pkgs {
+		if use.scope != modScope {
+			uniform = false
+			break
+		}
+	}
+	if uniform {
+		return
+	}
+
+	for _, pkg := range cfg.Packages {
+		s := pkgs[pkg].scope
+		if s&scopeBuild != 0 {
+			continue
+		}
+		if s&scopeTest != 0 {
+			cfg.TestPackages = append(cfg.TestPackages, pkg)
+		}
+		if s&scopeTool != 0 {
+			cfg.ToolPackages = append(cfg.ToolPackages, pkg)
+		}
+	}
+}
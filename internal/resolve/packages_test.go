@@ -3,6 +3,7 @@ package resolve
 import (
 	"testing"
 
+	"github.com/purpleclay/go-overlay/internal/mod"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -59,3 +60,82 @@ func TestMergePackagesNormalizesWhenRightIsEmpty(t *testing.T) {
 	expected := []string{"golang.org/x/sys/unix", "golang.org/x/sys/windows"}
 	assert.Equal(t, expected, MergePackages(left, nil))
 }
+
+func TestModulePackagesAnnotate(t *testing.T) {
+	pkgs := make(modulePackages)
+	pkgs.add("github.com/fatih/color", "github.com/fatih/color", scopeBuild|scopeTest, 0)
+	pkgs.add("golang.org/x/sys", "golang.org/x/sys/unix", scopeBuild|scopeTest, 0)
+	pkgs.add("golang.org/x/sys", "golang.org/x/sys/windows", scopeTest, 0)
+	pkgs.add("github.com/stretchr/testify", "github.com/stretchr/testify/assert", scopeTest, 0)
+	pkgs.add("github.com/stretchr/testify", "github.com/stretchr/testify/require", scopeTest, 0)
+	pkgs.add("golang.org/x/tools", "golang.org/x/tools/cmd/stringer", scopeTool, 0)
+	pkgs.add("golang.org/x/tools", "golang.org/x/tools/go/packages", scopeTest|scopeTool, 0)
+
+	annotate := func(path string) mod.ModuleConfig {
+		cfg := mod.ModuleConfig{Path: path}
+		pkgs.annotate(&cfg)
+		return cfg
+	}
+
+	color := annotate("github.com/fatih/color")
+	assert.Equal(t, []string{"github.com/fatih/color"}, color.Packages)
+	assert.Empty(t, color.Scope)
+	assert.Empty(t, color.TestPackages)
+
+	sys := annotate("golang.org/x/sys")
+	assert.Empty(t, sys.Scope)
+	assert.Equal(t, []string{"golang.org/x/sys/windows"}, sys.TestPackages)
+	assert.Empty(t, sys.ToolPackages)
+
+	testify := annotate("github.com/stretchr/testify")
+	assert.Equal(t, []string{"test"}, testify.Scope)
+	assert.Empty(t, testify.TestPackages)
+
+	tools := annotate("golang.org/x/tools")
+	assert.Equal(t, []string{"test", "tool"}, tools.Scope)
+	assert.Equal(t, []string{"golang.org/x/tools/go/packages"}, tools.TestPackages)
+	assert.Equal(t, []string{"golang.org/x/tools/cmd/stringer", "golang.org/x/tools/go/packages"}, tools.ToolPackages)
+}
+
+func TestParsePackagesByModuleSkipsFeatureColumns(t *testing.T) {
+	output := "github.com/mattn/go-sqlite3\tgithub.com/mattn/go-sqlite3\tcgo\n" +
+		"github.com/purpleclay/assets\tgithub.com/purpleclay/assets\tcgo\tembed"
+
+	result := ParsePackagesByModule(output)
+
+	assert.Equal(t, []string{"github.com/mattn/go-sqlite3"}, result["github.com/mattn/go-sqlite3"])
+	assert.Equal(t, []string{"github.com/purpleclay/assets"}, result["github.com/purpleclay/assets"])
+}
+
+func TestModulePackagesAnnotateFeatures(t *testing.T) {
+	linux := make(modulePackages)
+	linux.addListing("github.com/mattn/go-sqlite3\tgithub.com/mattn/go-sqlite3\tcgo\n"+
+		"github.com/purpleclay/assets\tgithub.com/purpleclay/assets/web\tembed\n"+
+		"github.com/purpleclay/assets\tgithub.com/purpleclay/assets", scopeBuild)
+
+	windows := make(modulePackages)
+	windows.addListing("github.com/mattn/go-sqlite3\tgithub.com/mattn/go-sqlite3\n"+
+		"github.com/fatih/color\tgithub.com/fatih/color", scopeBuild)
+
+	pkgs := make(modulePackages)
+	pkgs.merge(linux)
+	pkgs.merge(windows)
+
+	annotate := func(path string) mod.ModuleConfig {
+		cfg := mod.ModuleConfig{Path: path}
+		pkgs.annotate(&cfg)
+		return cfg
+	}
+
+	sqlite := annotate("github.com/mattn/go-sqlite3")
+	assert.True(t, sqlite.Cgo)
+	assert.False(t, sqlite.Embed)
+
+	assets := annotate("github.com/purpleclay/assets")
+	assert.False(t, assets.Cgo)
+	assert.True(t, assets.Embed)
+
+	color := annotate("github.com/fatih/color")
+	assert.False(t, color.Cgo)
+	assert.False(t, color.Embed)
+}
@@ -0,0 +1,308 @@
+package resolve
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/purpleclay/conker/pool"
+	"github.com/purpleclay/go-overlay/internal/goproxy"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/sumdb/dirhash"
+	modzip "golang.org/x/mod/zip"
+)
+
+// WithProxy hashes remote modules straight from the source archives served
+// by fetch, instead of extracting every module into the module cache with
+// `go mod download` and hashing the extracted directory. Modules that must
+// be fetched directly, or that have no go.sum entry to verify against, are
+// still downloaded with the go command.
+func WithProxy(fetch Fetcher) Option {
+	return func(r *Resolver) {
+		r.proxy = fetch
+	}
+}
+
+// WithZipURLs records the URL and SHA-256 hash of each zip fetched by
+// WithProxy, so the module can later be fetched without the go command.
+// Only zips served over HTTP(S) are recorded.
+func WithZipURLs() Option {
+	return func(r *Resolver) {
+		r.zipURLs = true
+	}
+}
+
+// zipSource is implemented by fetchers that report where each zip was
+// served from.
+type zipSource interface {
+	ZipFrom(ctx context.Context, path, version string) (io.ReadCloser, string, error)
+}
+
+// listedModule is one entry from `go list -m -json`.
+//
+//nolint:tagliatelle
+type listedModule struct {
+	Path    string        `json:"Path"`
+	Version string        `json:"Version"`
+	Main    bool          `json:"Main"`
+	GoMod   string        `json:"GoMod"`
+	Replace *listedModule `json:"Replace"`
+	Error   *struct {
+		Err string `json:"Err"`
+	} `json:"Error"`
+}
+
+func parseListModulesOutput(out string) ([]listedModule, error) {
+	var listed []listedModule
+	dec := json.NewDecoder(strings.NewReader(out))
+	for {
+		var m listedModule
+		if err := dec.Decode(&m); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		if m.Error != nil {
+			return nil, fmt.Errorf("failed to load %s@%s: %s", m.Path, m.Version, m.Error.Err)
+		}
+		listed = append(listed, m)
+	}
+	return listed, nil
+}
+
+// proxyDownload is a module fetched for hashing, or one left for the go
+// command to download.
+type proxyDownload struct {
+	ModuleDownload
+	direct bool
+}
+
+// proxyDownloads fetches the zip of every module `go mod download` would
+// download, without extracting them. The modules are found with
+// `go list -m all`, which only needs their go.mod files. Flags, such as
+// -modfile, are passed to every go command run.
+func (r *Resolver) proxyDownloads(ctx context.Context, dir string, env, flags []string, mains *mainModules) ([]ModuleDownload, error) {
+	endList := r.span(ctx, "download", "go list -m all", map[string]any{"dir": dir})
+	out, err := r.exec.Run(ctx, append(append([]string{"go", "list", "-m"}, flags...), "-json", "all"), dir, env)
+	endList()
+	if err != nil {
+		return nil, err
+	}
+
+	listed, err := parseListModulesOutput(out)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make(map[string]string, len(listed))
+	byPath := make(map[string]listedModule, len(listed))
+	for _, m := range listed {
+		if !m.Main {
+			selected[m.Path] = m.Version
+			byPath[m.Path] = m
+		}
+	}
+
+	defer r.span(ctx, "download", "proxy", map[string]any{"dir": dir})()
+
+	p := pool.NewWithResults[proxyDownload]().WithMaxGoroutines(8).WithContext(ctx)
+	for _, v := range mains.downloadSet(selected) {
+		target := byPath[v.Path]
+		if target.Replace != nil {
+			// Local replacements are never downloaded.
+			if target.Replace.Version == "" {
+				continue
+			}
+			target = *target.Replace
+		}
+
+		p.Go(func(ctx context.Context) (proxyDownload, error) {
+			return r.fetchZip(ctx, mains.sums, target)
+		})
+	}
+
+	fetched, err := p.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		downloads []ModuleDownload
+		direct    []string
+	)
+	for _, dl := range fetched {
+		if dl.direct {
+			direct = append(direct, dl.Path+"@"+dl.Version)
+			continue
+		}
+		downloads = append(downloads, dl.ModuleDownload)
+	}
+
+	if len(direct) > 0 {
+		endDownload := r.span(ctx, "download", "go mod download", map[string]any{"dir": dir, "modules": len(direct)})
+		args := append(append(append([]string{"go", "mod", "download"}, flags...), "-json"), direct...)
+		out, err := r.exec.Run(ctx, args, dir, env)
+		endDownload()
+		if err != nil {
+			return nil, err
+		}
+
+		downloaded, err := ParseDownloadOutput(out)
+		if err != nil {
+			return nil, err
+		}
+		downloads = append(downloads, downloaded...)
+	}
+
+	return downloads, nil
+}
+
+// fetchZip fetches the zip of a module and verifies it against go.sum,
+// hashing it as it is read rather than staging it on disk. Modules the proxy
+// must not serve, and those without a go.sum entry, which the go command
+// would check against the checksum database, are marked as direct.
+func (r *Resolver) fetchZip(ctx context.Context, sums GoSums, m listedModule) (proxyDownload, error) {
+	dl := proxyDownload{ModuleDownload: ModuleDownload{Path: m.Path, Version: m.Version, GoMod: m.GoMod}}
+	if _, ok := sums[m.Path+" "+m.Version]; !ok {
+		r.log.Printf("download: %s@%s has no go.sum entry, using the go command", m.Path, m.Version)
+		dl.direct = true
+		return dl, nil
+	}
+
+	v := module.Version{Path: m.Path, Version: m.Version}
+	rc, url, err := openZip(ctx, r.proxy, v)
+	if errors.Is(err, goproxy.ErrDirect) {
+		r.log.Printf("download: %s, using the go command", err)
+		dl.direct = true
+		return dl, nil
+	}
+	if err != nil {
+		return dl, err
+	}
+	defer rc.Close()
+
+	// A module zip is read through its central directory at the end, so it
+	// is held in memory, while its SHA-256 is computed as it streams in.
+	zipHash := sha256.New()
+	data, err := io.ReadAll(io.LimitReader(io.TeeReader(rc, zipHash), modzip.MaxZipFile+1))
+	if err != nil {
+		return dl, fmt.Errorf("failed to download %s@%s: %w", m.Path, m.Version, err)
+	}
+	if len(data) > modzip.MaxZipFile {
+		return dl, fmt.Errorf("invalid module zip %s: larger than %d bytes", url, modzip.MaxZipFile)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return dl, fmt.Errorf("invalid module zip %s: %w", url, err)
+	}
+
+	hash, err := hashZipReader(zr)
+	if err != nil {
+		return dl, err
+	}
+	if err := sums.verify(m.Path, m.Version, hash); err != nil {
+		return dl, err
+	}
+	if err := checkZipReader(zr, v); err != nil {
+		return dl, fmt.Errorf("invalid module zip %s: %w", url, err)
+	}
+
+	dl.NARHash, err = r.timedHash(ctx, m.Path+"@"+m.Version, func() (string, error) {
+		return narHashZipReader(zr, v, url)
+	})
+	if err != nil {
+		return dl, fmt.Errorf("failed to hash downloaded module %s@%s: %w", m.Path, m.Version, err)
+	}
+
+	if r.zipURLs && (strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "http://")) {
+		dl.URL = url
+		dl.ZipHash = "sha256-" + base64.StdEncoding.EncodeToString(zipHash.Sum(nil))
+	}
+	return dl, nil
+}
+
+// hashZipReader computes the go.sum hash of a module zip, as
+// dirhash.HashZip does for a zip on disk.
+func hashZipReader(zr *zip.Reader) (string, error) {
+	files := make([]string, 0, len(zr.File))
+	byName := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files = append(files, f.Name)
+		byName[f.Name] = f
+	}
+	return dirhash.Hash1(files, func(name string) (io.ReadCloser, error) {
+		f := byName[name]
+		if f == nil {
+			return nil, fmt.Errorf("file %q not found in zip", name)
+		}
+		return f.Open()
+	})
+}
+
+// checkZipReader checks the file names and sizes of a module zip that has
+// matched its go.sum hash, repeating the parts of modzip.CheckZip its NAR
+// serialisation relies on.
+func checkZipReader(zr *zip.Reader, m module.Version) error {
+	prefix := m.Path + "@" + m.Version + "/"
+	var size uint64
+	for _, f := range zr.File {
+		name, ok := strings.CutPrefix(f.Name, prefix)
+		if !ok {
+			return fmt.Errorf("%s: path does not have prefix %q", f.Name, prefix)
+		}
+		name = strings.TrimSuffix(name, "/")
+		if name == "" {
+			continue
+		}
+		if err := module.CheckFilePath(name); err != nil {
+			return fmt.Errorf("%s: %w", f.Name, err)
+		}
+		size += f.UncompressedSize64
+		if size > modzip.MaxZipFile {
+			return fmt.Errorf("total uncompressed size of module contents too large (max size is %d bytes)", modzip.MaxZipFile)
+		}
+	}
+	return nil
+}
+
+// openZip opens the zip of a module from fetch, along with the URL it was
+// served from when fetch reports it.
+func openZip(ctx context.Context, fetch Fetcher, v module.Version) (io.ReadCloser, string, error) {
+	if src, ok := fetch.(zipSource); ok {
+		return src.ZipFrom(ctx, v.Path, v.Version)
+	}
+	rc, err := fetch.Zip(ctx, v.Path, v.Version)
+	return rc, "", err
+}
+
+// downloadZip copies the zip of a module from fetch into a new file in dir,
+// for extraction into the module cache.
+func downloadZip(ctx context.Context, fetch Fetcher, dir string, v module.Version) (string, error) {
+	rc, _, err := openZip(ctx, fetch, v)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	f, err := os.CreateTemp(dir, "*.zip")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, rc); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to download %s@%s: %w", v.Path, v.Version, err)
+	}
+	return f.Name(), f.Close()
+}
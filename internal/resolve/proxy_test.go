@@ -0,0 +1,100 @@
+package resolve
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/sumdb/dirhash"
+	modzip "golang.org/x/mod/zip"
+)
+
+// zipFetcher serves a single module zip, reporting the URL it came from.
+type zipFetcher struct {
+	fakeFetcher
+	data []byte
+	url  string
+}
+
+func (f *zipFetcher) ZipFrom(_ context.Context, _, _ string) (io.ReadCloser, string, error) {
+	return io.NopCloser(bytes.NewReader(f.data)), f.url, nil
+}
+
+func createModuleZip(t *testing.T, m module.Version, files map[string]string) []byte {
+	t.Helper()
+	src := t.TempDir()
+	for name, content := range files {
+		writeTestFile(t, src, name, content)
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, modzip.CreateFromDir(&buf, m, src))
+	return buf.Bytes()
+}
+
+func TestFetchZipHashesWithoutStaging(t *testing.T) {
+	m := module.Version{Path: "example.com/zipped", Version: "v1.0.0"}
+	data := createModuleZip(t, m, map[string]string{
+		"go.mod":    "module example.com/zipped\n",
+		"zipped.go": "package zipped\n",
+		"a/b.go":    "package a\n",
+	})
+
+	zipFile := filepath.Join(t.TempDir(), "v1.0.0.zip")
+	require.NoError(t, os.WriteFile(zipFile, data, 0o644))
+	h1, err := dirhash.HashZip(zipFile, dirhash.Hash1)
+	require.NoError(t, err)
+	narHash, err := NARHashZip(zipFile, m)
+	require.NoError(t, err)
+	sum := sha256.Sum256(data)
+
+	url := "https://proxy.example.com/example.com/zipped/@v/v1.0.0.zip"
+	r := New(&fakeExecutor{}, WithProxy(&zipFetcher{data: data, url: url}), WithZipURLs())
+	dl, err := r.fetchZip(context.Background(), GoSums{m.Path + " " + m.Version: h1}, listedModule{Path: m.Path, Version: m.Version})
+	require.NoError(t, err)
+
+	assert.False(t, dl.direct)
+	assert.Empty(t, dl.Zip)
+	assert.Equal(t, narHash, dl.NARHash)
+	assert.Equal(t, url, dl.URL)
+	assert.Equal(t, "sha256-"+base64.StdEncoding.EncodeToString(sum[:]), dl.ZipHash)
+}
+
+func TestFetchZipRejectsChecksumMismatch(t *testing.T) {
+	m := module.Version{Path: "example.com/zipped", Version: "v1.0.0"}
+	data := createModuleZip(t, m, map[string]string{"go.mod": "module example.com/zipped\n"})
+
+	r := New(&fakeExecutor{}, WithProxy(&zipFetcher{data: data}))
+	_, err := r.fetchZip(context.Background(), GoSums{m.Path + " " + m.Version: "h1:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="}, listedModule{Path: m.Path, Version: m.Version})
+	require.ErrorContains(t, err, "checksum mismatch")
+}
+
+func TestFetchZipRejectsFilesOutsideModule(t *testing.T) {
+	m := module.Version{Path: "example.com/zipped", Version: "v1.0.0"}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("example.com/other@v1.0.0/go.mod")
+	require.NoError(t, err)
+	_, err = w.Write([]byte("module example.com/other\n"))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	h1, err := hashZipReader(zr)
+	require.NoError(t, err)
+
+	r := New(&fakeExecutor{}, WithProxy(&zipFetcher{data: buf.Bytes()}))
+	_, err = r.fetchZip(context.Background(), GoSums{m.Path + " " + m.Version: h1}, listedModule{Path: m.Path, Version: m.Version})
+	require.ErrorContains(t, err, `does not have prefix "example.com/zipped@v1.0.0/"`)
+}
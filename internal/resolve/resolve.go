@@ -6,12 +6,14 @@ import (
 	"maps"
 	"os"
 	"path/filepath"
-	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/purpleclay/conker/pool"
 	"github.com/purpleclay/go-overlay/internal/mod"
+	"github.com/purpleclay/go-overlay/internal/notes"
+	"github.com/purpleclay/go-overlay/internal/trace"
 	"golang.org/x/mod/modfile"
 	"golang.org/x/mod/semver"
 )
@@ -20,12 +22,48 @@ import (
 // commands go through the Executor interface, making the resolver testable
 // with injected output.
 type Resolver struct {
-	exec Executor
+	exec     Executor
+	log      *Logger
+	trace    *trace.Recorder
+	modCache *ModCache
+	proxy    Fetcher
+	zipURLs  bool
+}
+
+type Option func(*Resolver)
+
+// WithLogger reports per-module NAR hash timings to log.
+func WithLogger(log *Logger) Option {
+	return func(r *Resolver) {
+		r.log = log
+	}
+}
+
+// WithTrace records resolution phases (download, per-platform package
+// listing, hashing and local module git listing) as trace spans.
+func WithTrace(rec *trace.Recorder) Option {
+	return func(r *Resolver) {
+		r.trace = rec
+	}
+}
+
+// WithModCache resolves against an isolated module cache instead of the
+// user's shared GOMODCACHE. Downloaded modules are sealed read-only before
+// they are hashed.
+func WithModCache(cache *ModCache) Option {
+	return func(r *Resolver) {
+		r.modCache = cache
+		r.exec = cache.Wrap(r.exec)
+	}
 }
 
 // New creates a Resolver with the given executor.
-func New(exec Executor) *Resolver {
-	return &Resolver{exec: exec}
+func New(exec Executor, opts ...Option) *Resolver {
+	r := &Resolver{exec: exec}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
 // ValidatePlatforms checks that all given platform strings are supported by
@@ -37,7 +75,7 @@ func (r *Resolver) ValidatePlatforms(ctx context.Context, platforms []string) er
 
 	out, err := r.exec.Run(ctx, []string{"go", "tool", "dist", "list"}, ".", nil)
 	if err != nil {
-		return fmt.Errorf("failed to get supported platforms: %w", err)
+		return Classify(fmt.Errorf("failed to get supported platforms: %w", err))
 	}
 
 	supported := make(map[string]bool)
@@ -61,13 +99,24 @@ func (r *Resolver) ValidatePlatforms(ctx context.Context, platforms []string) er
 	return nil
 }
 
-// ResolveModule resolves all dependencies for a single Go module.
-func (r *Resolver) ResolveModule(ctx context.Context, goMod *mod.GoModFile, platforms []string) ([]mod.ModuleConfig, error) {
+// ResolveModule resolves all dependencies for a single Go module. Packages
+// are attributed by following the imports of the packages matched by
+// patterns, relative to the module directory, or of every package in the
+// module without them. Failures matching a known class are returned as a
+// *ClassifiedError.
+func (r *Resolver) ResolveModule(ctx context.Context, goMod *mod.GoModFile, platforms []string, patterns ...string) ([]mod.ModuleConfig, error) {
+	modules, err := r.resolveModule(ctx, goMod, platforms, patterns)
+	return modules, Classify(err)
+}
+
+func (r *Resolver) resolveModule(ctx context.Context, goMod *mod.GoModFile, platforms, patterns []string) ([]mod.ModuleConfig, error) {
 	if platforms == nil {
 		platforms = mod.DefaultPlatforms()
 	}
 
-	pkgsByMod, err := r.packagesByModule(ctx, goMod, platforms)
+	defer r.trace.Start("resolve", goMod.ModulePath, map[string]any{"dir": goMod.Dir})()
+
+	pkgsByMod, err := r.packagesByModule(ctx, goMod, platforms, patterns)
 	if err != nil {
 		return nil, err
 	}
@@ -77,6 +126,13 @@ func (r *Resolver) ResolveModule(ctx context.Context, goMod *mod.GoModFile, plat
 		return nil, err
 	}
 
+	return r.assembleModule(ctx, goMod, downloads, pkgsByMod)
+}
+
+// assembleModule hashes the downloaded and locally replaced modules of a
+// single Go module and attributes their packages, producing the sorted
+// module list recorded in the manifest.
+func (r *Resolver) assembleModule(ctx context.Context, goMod *mod.GoModFile, downloads []ModuleDownload, pkgsByMod modulePackages) ([]mod.ModuleConfig, error) {
 	modules, err := r.resolveRemoteModules(ctx, goMod.RemoteReplacements(), downloads, pkgsByMod)
 	if err != nil {
 		return nil, err
@@ -98,20 +154,20 @@ func (r *Resolver) ResolveModule(ctx context.Context, goMod *mod.GoModFile, plat
 // ResolveWorkspace resolves dependencies across all modules in a Go workspace.
 // It runs a single go mod download from the workspace root so Go's MVS applies
 // across all members, then gathers per-member package attribution with GOWORK=off.
-func (r *Resolver) ResolveWorkspace(ctx context.Context, goWork *mod.GoWorkFile, platforms []string) ([]mod.ModuleConfig, error) {
+// Patterns, relative to the workspace root, restrict attribution to the
+// imports of the packages they match, as with ResolveModule.
+// Failures matching a known class are returned as a *ClassifiedError.
+func (r *Resolver) ResolveWorkspace(ctx context.Context, goWork *mod.GoWorkFile, platforms []string, patterns ...string) ([]mod.ModuleConfig, error) {
+	modules, err := r.resolveWorkspace(ctx, goWork, platforms, patterns)
+	return modules, Classify(err)
+}
+
+func (r *Resolver) resolveWorkspace(ctx context.Context, goWork *mod.GoWorkFile, platforms, patterns []string) ([]mod.ModuleConfig, error) {
 	if platforms == nil {
 		platforms = mod.DefaultPlatforms()
 	}
 
-	members, err := goWork.ParseMembers()
-	if err != nil {
-		return nil, err
-	}
-
-	workspaceMembers := make(map[string]string, len(members))
-	for _, m := range members {
-		workspaceMembers[m.ModulePath] = m.Dir
-	}
+	defer r.trace.Start("resolve", mod.GoWorkFilename, map[string]any{"dir": goWork.Dir})()
 
 	// Download from the workspace root with GOWORK active so the Go toolchain
 	// applies workspace-level MVS, producing one authoritative set of resolved
@@ -123,6 +179,25 @@ func (r *Resolver) ResolveWorkspace(ctx context.Context, goWork *mod.GoWorkFile,
 
 	// Parse each member go.mod once up front so both the packages and local
 	// replacement passes can reuse the result without duplicate file I/O.
+	memberGoMods, err := parseMemberGoMods(goWork)
+	if err != nil {
+		return nil, err
+	}
+
+	// List packages for all workspace members in a single go list invocation per
+	// platform from the workspace root, keeping GOWORK active so workspace-level
+	// replace directives (including local replaces) are respected.
+	pkgsByMod, err := r.packagesByWorkspace(ctx, goWork, memberGoMods, platforms, patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.assembleWorkspace(ctx, goWork, memberGoMods, downloads, pkgsByMod)
+}
+
+// parseMemberGoMods parses the go.mod of every workspace member, keyed by
+// the member directory as written in go.work.
+func parseMemberGoMods(goWork *mod.GoWorkFile) (map[string]*mod.GoModFile, error) {
 	memberGoMods := make(map[string]*mod.GoModFile, len(goWork.Modules))
 	for _, modDir := range goWork.Modules {
 		goModPath := filepath.Join(goWork.Dir, modDir, mod.GoModFilename)
@@ -132,15 +207,23 @@ func (r *Resolver) ResolveWorkspace(ctx context.Context, goWork *mod.GoWorkFile,
 		}
 		memberGoMods[modDir] = goMod
 	}
+	return memberGoMods, nil
+}
 
-	// List packages for all workspace members in a single go list invocation per
-	// platform from the workspace root, keeping GOWORK active so workspace-level
-	// replace directives (including local replaces) are respected.
-	pkgsByMod, err := r.packagesByWorkspace(ctx, goWork, memberGoMods, platforms)
+// assembleWorkspace hashes the downloaded and locally replaced modules of a
+// workspace and attributes their packages. Workspace members are recorded
+// as local modules without a hash or package list.
+func (r *Resolver) assembleWorkspace(ctx context.Context, goWork *mod.GoWorkFile, memberGoMods map[string]*mod.GoModFile, downloads []ModuleDownload, pkgsByMod modulePackages) ([]mod.ModuleConfig, error) {
+	members, err := goWork.ParseMembers()
 	if err != nil {
 		return nil, err
 	}
 
+	workspaceMembers := make(map[string]string, len(members))
+	for _, m := range members {
+		workspaceMembers[m.ModulePath] = m.Dir
+	}
+
 	// Gather remote replacements from all members, workspace-level taking precedence.
 	remoteRepls := make(map[string]mod.Replacement)
 	for _, modDir := range goWork.Modules {
@@ -187,6 +270,9 @@ func (r *Resolver) ResolveWorkspace(ctx context.Context, goWork *mod.GoWorkFile,
 		if localPath, isWorkspace := workspaceMembers[m.Path]; isWorkspace {
 			m.Hash = ""
 			m.Packages = nil
+			m.Scope = nil
+			m.TestPackages = nil
+			m.ToolPackages = nil
 			m.Local = localPath
 		}
 		modules = append(modules, m)
@@ -199,8 +285,8 @@ func (r *Resolver) ResolveWorkspace(ctx context.Context, goWork *mod.GoWorkFile,
 	return modules, nil
 }
 
-func (r *Resolver) packagesByModule(ctx context.Context, goMod *mod.GoModFile, platforms []string) (map[string][]string, error) {
-	p := pool.NewWithResults[map[string][]string]().WithContext(ctx)
+func (r *Resolver) packagesByModule(ctx context.Context, goMod *mod.GoModFile, platforms, patterns []string) (modulePackages, error) {
+	p := pool.NewWithResults[modulePackages]().WithContext(ctx)
 
 	seen := make(map[string]struct{}, len(platforms))
 	for _, plat := range platforms {
@@ -212,8 +298,8 @@ func (r *Resolver) packagesByModule(ctx context.Context, goMod *mod.GoModFile, p
 			continue
 		}
 		seen[plat] = struct{}{}
-		p.Go(func(ctx context.Context) (map[string][]string, error) {
-			return r.packagesByModuleForPlatform(ctx, goMod, goos, goarch)
+		p.Go(func(ctx context.Context) (modulePackages, error) {
+			return r.packagesByModuleForPlatform(ctx, goMod, patterns, goos, goarch)
 		})
 	}
 
@@ -222,61 +308,71 @@ func (r *Resolver) packagesByModule(ctx context.Context, goMod *mod.GoModFile, p
 		return nil, err
 	}
 
-	merged := make(map[string][]string)
+	merged := make(modulePackages)
 	for _, result := range results {
-		for m, pkgs := range result {
-			merged[m] = append(merged[m], pkgs...)
-		}
-	}
-
-	for modPath := range merged {
-		sort.Strings(merged[modPath])
-		merged[modPath] = slices.Compact(merged[modPath])
+		merged.merge(result)
 	}
-
 	return merged, nil
 }
 
-func (r *Resolver) packagesByModuleForPlatform(ctx context.Context, goMod *mod.GoModFile, goos, goarch string) (map[string][]string, error) {
-	listFmt := fmt.Sprintf(`{{if not .Standard}}{{if .Module}}{{if ne .Module.Path "%s"}}{{.Module.Path}}{{"\t"}}{{.ImportPath}}{{end}}{{end}}{{end}}`, goMod.ModulePath)
+// listPasses are the `go list` invocations that attribute packages to a
+// scope. Listing with and without -test separates the packages the build
+// needs from those pulled in only by _test.go files.
+var listPasses = []struct {
+	flags []string
+	scope scope
+}{
+	{flags: []string{"-deps"}, scope: scopeBuild},
+	{flags: []string{"-deps", "-test"}, scope: scopeTest},
+}
 
-	args := []string{
-		"go", "list", "-deps", "-test", "-f", listFmt, "./...",
+func (r *Resolver) packagesByModuleForPlatform(ctx context.Context, goMod *mod.GoModFile, patterns []string, goos, goarch string) (modulePackages, error) {
+	// An alternate modfile, such as tools.mod, usually requires just the
+	// tools it declares, so without patterns only those are listed.
+	if len(patterns) == 0 && !goMod.IsAlternate() {
+		patterns = []string{"./..."}
 	}
 
+	listFmt := listFormat(goMod.ModulePath)
+
 	// GOWORK=off ensures this module is processed independently, which is
 	// essential for workspaces where each module's dependencies must be
 	// resolved in isolation before being merged at the workspace level.
+	// CGO_ENABLED=1 lists cgo files for every platform, whatever the host
+	// and its C compiler, so the manifest reflects only the source.
 	env := []string{
 		"GOWORK=off",
 		"GOOS=" + goos,
 		"GOARCH=" + goarch,
+		"CGO_ENABLED=1",
 	}
 
-	out, err := r.exec.Run(ctx, args, goMod.Dir, env)
-	if err != nil {
-		return nil, err
-	}
+	defer r.span(ctx, "list", goos+"/"+goarch, map[string]any{"dir": goMod.Dir})()
 
-	pkgsByMod := ParsePackagesByModule(out)
+	pkgsByMod := make(modulePackages)
+	if len(patterns) > 0 {
+		for _, l := range listPasses {
+			args := append(append(append(append([]string{"go", "list"}, goMod.ModFileFlags()...), l.flags...), "-f", listFmt), patterns...)
+			out, err := r.exec.Run(ctx, args, goMod.Dir, env)
+			if err != nil {
+				return nil, err
+			}
+			pkgsByMod.addListing(out, l.scope)
+		}
+	}
 
 	// Include tool dependencies (Go 1.24+) so their packages appear in the
 	// module-to-package mapping and are listed in modules.txt. A separate
 	// invocation without -test avoids pulling in each tool's test-only
 	// dependencies.
 	if goMod.HasTools() {
-		toolArgs := []string{
-			"go", "list", "-deps", "-f", listFmt, "tool",
-		}
+		toolArgs := append(append([]string{"go", "list"}, goMod.ModFileFlags()...), "-deps", "-f", listFmt, "tool")
 
 		toolOut, err := r.exec.Run(ctx, toolArgs, goMod.Dir, env)
 		if err != nil {
 			return nil, err
 		}
-
-		for m, pkgs := range ParsePackagesByModule(toolOut) {
-			pkgsByMod[m] = append(pkgsByMod[m], pkgs...)
-		}
+		pkgsByMod.addListing(toolOut, scopeTool)
 	}
 
 	return pkgsByMod, nil
@@ -286,8 +382,8 @@ func (r *Resolver) packagesByModuleForPlatform(ctx context.Context, goMod *mod.G
 // members in a single go list invocation per platform, run from the workspace
 // root with GOWORK active. This ensures workspace-level replace directives
 // (including local replaces) are respected, unlike per-member GOWORK=off listing.
-func (r *Resolver) packagesByWorkspace(ctx context.Context, goWork *mod.GoWorkFile, memberGoMods map[string]*mod.GoModFile, platforms []string) (map[string][]string, error) {
-	p := pool.NewWithResults[map[string][]string]().WithContext(ctx)
+func (r *Resolver) packagesByWorkspace(ctx context.Context, goWork *mod.GoWorkFile, memberGoMods map[string]*mod.GoModFile, platforms, patterns []string) (modulePackages, error) {
+	p := pool.NewWithResults[modulePackages]().WithContext(ctx)
 
 	seen := make(map[string]struct{}, len(platforms))
 	for _, plat := range platforms {
@@ -299,8 +395,8 @@ func (r *Resolver) packagesByWorkspace(ctx context.Context, goWork *mod.GoWorkFi
 			continue
 		}
 		seen[plat] = struct{}{}
-		p.Go(func(ctx context.Context) (map[string][]string, error) {
-			return r.packagesByWorkspaceForPlatform(ctx, goWork, memberGoMods, goos, goarch)
+		p.Go(func(ctx context.Context) (modulePackages, error) {
+			return r.packagesByWorkspaceForPlatform(ctx, goWork, memberGoMods, patterns, goos, goarch)
 		})
 	}
 
@@ -309,45 +405,43 @@ func (r *Resolver) packagesByWorkspace(ctx context.Context, goWork *mod.GoWorkFi
 		return nil, err
 	}
 
-	merged := make(map[string][]string)
+	merged := make(modulePackages)
 	for _, result := range results {
-		for m, pkgs := range result {
-			merged[m] = append(merged[m], pkgs...)
-		}
-	}
-
-	for modPath := range merged {
-		sort.Strings(merged[modPath])
-		merged[modPath] = slices.Compact(merged[modPath])
+		merged.merge(result)
 	}
-
 	return merged, nil
 }
 
-func (r *Resolver) packagesByWorkspaceForPlatform(ctx context.Context, goWork *mod.GoWorkFile, memberGoMods map[string]*mod.GoModFile, goos, goarch string) (map[string][]string, error) {
-	// Build import path patterns for every workspace member so a single go list
-	// spans the full workspace, keeping GOWORK active so workspace-level replace
-	// directives are respected.
-	patterns := make([]string, 0, len(memberGoMods))
-	for _, goMod := range memberGoMods {
-		patterns = append(patterns, goMod.ModulePath+"/...")
+func (r *Resolver) packagesByWorkspaceForPlatform(ctx context.Context, goWork *mod.GoWorkFile, memberGoMods map[string]*mod.GoModFile, patterns []string, goos, goarch string) (modulePackages, error) {
+	// Without patterns, build import path patterns for every workspace member
+	// so a single go list spans the full workspace, keeping GOWORK active so
+	// workspace-level replace directives are respected.
+	if len(patterns) == 0 {
+		for _, goMod := range memberGoMods {
+			patterns = append(patterns, goMod.ModulePath+"/...")
+		}
+		sort.Strings(patterns)
 	}
-	sort.Strings(patterns)
 
-	listFmt := `{{if not .Standard}}{{if .Module}}{{.Module.Path}}{{"\t"}}{{.ImportPath}}{{end}}{{end}}`
-	args := append([]string{"go", "list", "-deps", "-test", "-f", listFmt}, patterns...)
+	listFmt := listFormat("")
 
 	env := []string{
 		"GOOS=" + goos,
 		"GOARCH=" + goarch,
+		"CGO_ENABLED=1",
 	}
 
-	out, err := r.exec.Run(ctx, args, goWork.Dir, env)
-	if err != nil {
-		return nil, err
-	}
+	defer r.span(ctx, "list", goos+"/"+goarch, map[string]any{"dir": goWork.Dir})()
 
-	pkgsByMod := ParsePackagesByModule(out)
+	pkgsByMod := make(modulePackages)
+	for _, l := range listPasses {
+		args := append(append(append([]string{"go", "list"}, l.flags...), "-f", listFmt), patterns...)
+		out, err := r.exec.Run(ctx, args, goWork.Dir, env)
+		if err != nil {
+			return nil, err
+		}
+		pkgsByMod.addListing(out, l.scope)
+	}
 
 	// Tool packages are module-scoped and cannot be batched from the workspace
 	// root, so they are listed per-member with GOWORK=off.
@@ -355,58 +449,95 @@ func (r *Resolver) packagesByWorkspaceForPlatform(ctx context.Context, goWork *m
 		"GOWORK=off",
 		"GOOS=" + goos,
 		"GOARCH=" + goarch,
+		"CGO_ENABLED=1",
 	}
 
 	for _, goMod := range memberGoMods {
 		if !goMod.HasTools() {
 			continue
 		}
-		toolFmt := fmt.Sprintf(`{{if not .Standard}}{{if .Module}}{{if ne .Module.Path "%s"}}{{.Module.Path}}{{"\t"}}{{.ImportPath}}{{end}}{{end}}{{end}}`, goMod.ModulePath)
-		toolOut, err := r.exec.Run(ctx, []string{"go", "list", "-deps", "-f", toolFmt, "tool"}, goMod.Dir, toolEnv)
+		toolOut, err := r.exec.Run(ctx, []string{"go", "list", "-deps", "-f", listFormat(goMod.ModulePath), "tool"}, goMod.Dir, toolEnv)
 		if err != nil {
 			return nil, err
 		}
-		for m, pkgs := range ParsePackagesByModule(toolOut) {
-			pkgsByMod[m] = append(pkgsByMod[m], pkgs...)
-		}
+		pkgsByMod.addListing(toolOut, scopeTool)
 	}
 
 	return pkgsByMod, nil
 }
 
+// downloadModules runs go mod download for a single module, or fetches its
+// modules from the proxy when one is configured.
 func (r *Resolver) downloadModules(ctx context.Context, goMod *mod.GoModFile) ([]ModuleDownload, error) {
-	args := []string{"go", "mod", "download", "-json"}
 	env := []string{"GOWORK=off"}
 
+	if r.proxy != nil {
+		mains, err := loadMainModule(goMod)
+		if err != nil {
+			return nil, err
+		}
+		return r.proxyDownloads(ctx, goMod.Dir, env, goMod.ModFileFlags(), mains)
+	}
+
+	args := append(append([]string{"go", "mod", "download"}, goMod.ModFileFlags()...), "-json")
+
+	defer r.span(ctx, "download", "go mod download", map[string]any{"dir": goMod.Dir})()
+
 	out, err := r.exec.Run(ctx, args, goMod.Dir, env)
 	if err != nil {
 		return nil, err
 	}
 
-	return ParseDownloadOutput(out)
+	downloads, err := ParseDownloadOutput(out)
+	return downloads, err
 }
 
 // downloadWorkspaceModules runs go mod download from the workspace root with
 // GOWORK active, letting the Go toolchain apply workspace-level MVS.
 func (r *Resolver) downloadWorkspaceModules(ctx context.Context, goWork *mod.GoWorkFile) ([]ModuleDownload, error) {
+	if r.proxy != nil {
+		mains, err := loadMainWorkspace(goWork)
+		if err != nil {
+			return nil, err
+		}
+		return r.proxyDownloads(ctx, goWork.Dir, nil, nil, mains)
+	}
+
 	args := []string{"go", "mod", "download", "-json"}
 
+	defer r.span(ctx, "download", "go mod download", map[string]any{"dir": goWork.Dir})()
+
 	out, err := r.exec.Run(ctx, args, goWork.Dir, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	return ParseDownloadOutput(out)
+	downloads, err := ParseDownloadOutput(out)
+	return downloads, err
 }
 
-func (r *Resolver) resolveRemoteModules(ctx context.Context, remoteReplacements map[string]mod.Replacement, downloads []ModuleDownload, pkgsByMod map[string][]string) ([]mod.ModuleConfig, error) {
+func (r *Resolver) resolveRemoteModules(ctx context.Context, remoteReplacements map[string]mod.Replacement, downloads []ModuleDownload, pkgsByMod modulePackages) ([]mod.ModuleConfig, error) {
 	p := pool.NewWithResults[mod.ModuleConfig]().WithMaxGoroutines(8).WithContext(ctx)
 
 	for _, meta := range downloads {
-		p.Go(func(_ context.Context) (mod.ModuleConfig, error) {
-			hash, err := NARHash(meta.Dir)
-			if err != nil {
-				return mod.ModuleConfig{}, fmt.Errorf("failed to hash downloaded module %s@%s: %w", meta.Path, meta.Version, err)
+		p.Go(func(ctx context.Context) (mod.ModuleConfig, error) {
+			if r.modCache != nil && meta.Dir != "" {
+				if err := r.modCache.Seal(meta.Dir); err != nil {
+					return mod.ModuleConfig{}, err
+				}
+			}
+
+			// Modules fetched from the proxy are hashed as they are read,
+			// without being extracted.
+			hash := meta.NARHash
+			if hash == "" {
+				var err error
+				hash, err = r.timedHash(ctx, meta.Path+"@"+meta.Version, func() (string, error) {
+					return NARHash(meta.Dir)
+				})
+				if err != nil {
+					return mod.ModuleConfig{}, fmt.Errorf("failed to hash downloaded module %s@%s: %w", meta.Path, meta.Version, err)
+				}
 			}
 
 			var goVersion string
@@ -425,21 +556,24 @@ func (r *Resolver) resolveRemoteModules(ctx context.Context, remoteReplacements
 				replacedPath = meta.Path
 			}
 
-			return mod.ModuleConfig{
+			cfg := mod.ModuleConfig{
 				Path:         path,
 				Version:      meta.Version,
-				Packages:     pkgsByMod[path],
 				Hash:         hash,
 				GoVersion:    goVersion,
 				ReplacedPath: replacedPath,
-			}, nil
+				URL:          meta.URL,
+				ZipHash:      meta.ZipHash,
+			}
+			pkgsByMod.annotate(&cfg)
+			return cfg, nil
 		})
 	}
 
 	return p.Wait()
 }
 
-func (r *Resolver) resolveLocalModules(ctx context.Context, goMod *mod.GoModFile, pkgsByMod map[string][]string) ([]mod.ModuleConfig, error) {
+func (r *Resolver) resolveLocalModules(ctx context.Context, goMod *mod.GoModFile, pkgsByMod modulePackages) ([]mod.ModuleConfig, error) {
 	localRepls := goMod.LocalReplacements()
 	if len(localRepls) == 0 {
 		return nil, nil
@@ -459,12 +593,14 @@ func (r *Resolver) resolveLocalModules(ctx context.Context, goMod *mod.GoModFile
 				return mod.ModuleConfig{}, fmt.Errorf("failed to resolve local module path %s: %w", repl.LocalPath, err)
 			}
 
-			tracked, err := GitTrackedFiles(ctx, r.exec, localDir)
+			tracked, err := r.gitTrackedFiles(ctx, localDir)
 			if err != nil {
 				return mod.ModuleConfig{}, fmt.Errorf("failed to list git tracked files for local module %s: %w", repl.LocalPath, err)
 			}
 
-			hash, err := NARHashGitTracked(localDir, tracked)
+			hash, err := r.timedHash(ctx, repl.OldPath+" => "+repl.LocalPath, func() (string, error) {
+				return NARHashGitTracked(localDir, tracked)
+			})
 			if err != nil {
 				return mod.ModuleConfig{}, fmt.Errorf("failed to hash local module %s: %w", repl.LocalPath, err)
 			}
@@ -482,14 +618,15 @@ func (r *Resolver) resolveLocalModules(ctx context.Context, goMod *mod.GoModFile
 				version = "v0.0.0"
 			}
 
-			return mod.ModuleConfig{
+			cfg := mod.ModuleConfig{
 				Path:      repl.OldPath,
 				Version:   version,
-				Packages:  pkgsByMod[repl.OldPath],
 				Hash:      hash,
 				GoVersion: goVersion,
 				Local:     repl.LocalPath,
-			}, nil
+			}
+			pkgsByMod.annotate(&cfg)
+			return cfg, nil
 		})
 	}
 
@@ -500,7 +637,7 @@ func (r *Resolver) resolveLocalModules(ctx context.Context, goMod *mod.GoModFile
 // local replace directives declared at the workspace level in go.work. These
 // are not visible to per-member go.mod parsing, so they must be resolved
 // separately using the workspace root as the base for relative path resolution.
-func (r *Resolver) resolveWorkspaceLocalModules(ctx context.Context, goWork *mod.GoWorkFile, memberGoMods map[string]*mod.GoModFile, downloads []ModuleDownload, pkgsByMod map[string][]string) ([]mod.ModuleConfig, error) {
+func (r *Resolver) resolveWorkspaceLocalModules(ctx context.Context, goWork *mod.GoWorkFile, memberGoMods map[string]*mod.GoModFile, downloads []ModuleDownload, pkgsByMod modulePackages) ([]mod.ModuleConfig, error) {
 	workspaceLocalRepls := goWork.LocalReplacements()
 	if len(workspaceLocalRepls) == 0 {
 		return nil, nil
@@ -537,12 +674,14 @@ func (r *Resolver) resolveWorkspaceLocalModules(ctx context.Context, goWork *mod
 				return mod.ModuleConfig{}, fmt.Errorf("failed to resolve workspace local module path %s: %w", repl.LocalPath, err)
 			}
 
-			tracked, err := GitTrackedFiles(ctx, r.exec, localDir)
+			tracked, err := r.gitTrackedFiles(ctx, localDir)
 			if err != nil {
 				return mod.ModuleConfig{}, fmt.Errorf("failed to list git tracked files for workspace local module %s: %w", repl.LocalPath, err)
 			}
 
-			hash, err := NARHashGitTracked(localDir, tracked)
+			hash, err := r.timedHash(ctx, repl.OldPath+" => "+repl.LocalPath, func() (string, error) {
+				return NARHashGitTracked(localDir, tracked)
+			})
 			if err != nil {
 				return mod.ModuleConfig{}, fmt.Errorf("failed to hash workspace local module %s: %w", repl.LocalPath, err)
 			}
@@ -563,16 +702,54 @@ func (r *Resolver) resolveWorkspaceLocalModules(ctx context.Context, goWork *mod
 				version = "v0.0.0"
 			}
 
-			return mod.ModuleConfig{
+			cfg := mod.ModuleConfig{
 				Path:      repl.OldPath,
 				Version:   version,
-				Packages:  pkgsByMod[repl.OldPath],
 				Hash:      hash,
 				GoVersion: goVersion,
 				Local:     repl.LocalPath,
-			}, nil
+			}
+			pkgsByMod.annotate(&cfg)
+			return cfg, nil
 		})
 	}
 
 	return p.Wait()
 }
+
+// timedHash runs hashFn within a hashing trace span and logs how long it took.
+func (r *Resolver) timedHash(ctx context.Context, name string, hashFn func() (string, error)) (string, error) {
+	end := r.span(ctx, "hash", name, nil)
+	start := time.Now()
+	hash, err := hashFn()
+	end()
+
+	r.log.Printf("hash: %s in %s", name, time.Since(start).Round(time.Millisecond))
+	return hash, err
+}
+
+func (r *Resolver) gitTrackedFiles(ctx context.Context, dir string) (map[string]struct{}, error) {
+	defer r.span(ctx, "git", "git ls-files", map[string]any{"dir": dir})()
+	return GitTrackedFiles(ctx, r.exec, dir)
+}
+
+// span opens a trace span and marks the matching phase as running against
+// the context, so a timeout can report what was in progress.
+func (r *Resolver) span(ctx context.Context, category, name string, args map[string]any) func() {
+	phase := name
+	switch category {
+	case "list":
+		phase = "go list (" + name + ")"
+	case "hash":
+		phase = "hashing " + name
+	case "git":
+		phase = fmt.Sprintf("git ls-files (%v)", args["dir"])
+	}
+
+	endTrace := r.trace.Start(category, name, args)
+	endPhase := notes.Begin(ctx, phase)
+	return func() {
+		endPhase()
+		endTrace()
+	}
+}
@@ -6,9 +6,11 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/purpleclay/go-overlay/internal/mod"
+	"github.com/purpleclay/go-overlay/internal/trace"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -297,3 +299,210 @@ go 1.25.4
 	assert.Empty(t, deps[0].Hash)
 	assert.Empty(t, deps[0].Packages)
 }
+
+// envExecutor records the environment of every go list run before
+// answering it from its canned responses.
+type envExecutor struct {
+	fakeExecutor
+	mu   sync.Mutex
+	envs [][]string
+}
+
+func (e *envExecutor) Run(ctx context.Context, args []string, dir string, env []string) (string, error) {
+	if len(args) > 1 && args[1] == "list" {
+		e.mu.Lock()
+		e.envs = append(e.envs, env)
+		e.mu.Unlock()
+	}
+	return e.fakeExecutor.Run(ctx, args, dir, env)
+}
+
+func TestResolveModuleListsOtherPlatformsWithCgo(t *testing.T) {
+	dir := t.TempDir()
+	goModPath := writeTestFile(t, dir, "go.mod", `
+module example.com/app
+
+go 1.25.4
+
+require github.com/mattn/go-sqlite3 v1.14.22
+`)
+
+	goMod, err := mod.ParseGoModFile(goModPath)
+	require.NoError(t, err)
+
+	exec := &envExecutor{fakeExecutor: fakeExecutor{
+		responses: map[string]string{
+			"go list": "github.com/mattn/go-sqlite3\tgithub.com/mattn/go-sqlite3\tcgo",
+			"go mod":  `{"Path":"github.com/mattn/go-sqlite3","Version":"v1.14.22","Dir":"testdata/module","GoMod":"testdata/module/go.mod"}`,
+		},
+	}}
+
+	deps, err := New(exec).ResolveModule(context.Background(), goMod, []string{"windows/arm64"})
+	require.NoError(t, err)
+	require.Len(t, deps, 1)
+	assert.True(t, deps[0].Cgo)
+
+	require.NotEmpty(t, exec.envs)
+	for _, env := range exec.envs {
+		assert.Subset(t, env, []string{"GOOS=windows", "GOARCH=arm64", "CGO_ENABLED=1"})
+	}
+}
+
+func TestResolveModuleRecordsTraceSpans(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "localmod/go.mod", "module example.com/localmod\n\ngo 1.25.4\n")
+	goModPath := writeTestFile(t, dir, "go.mod", `
+module example.com/app
+
+go 1.25.4
+
+require (
+	example.com/localmod v0.0.0
+	github.com/fatih/color v1.18.0
+)
+
+replace example.com/localmod => ./localmod
+`)
+
+	goMod, err := mod.ParseGoModFile(goModPath)
+	require.NoError(t, err)
+
+	exec := &fakeExecutor{
+		responses: map[string]string{
+			"go list":      `github.com/fatih/color	github.com/fatih/color`,
+			"go mod":       `{"Path":"github.com/fatih/color","Version":"v1.18.0","Dir":"testdata/module","GoMod":"testdata/module/go.mod"}`,
+			"git ls-files": "go.mod",
+		},
+	}
+
+	rec := trace.New()
+	r := New(exec, WithTrace(rec))
+	_, err = r.ResolveModule(context.Background(), goMod, []string{"linux/amd64", "darwin/arm64"})
+	require.NoError(t, err)
+
+	spans := make(map[string][]string)
+	for _, e := range rec.Events() {
+		spans[e.Category] = append(spans[e.Category], e.Name)
+	}
+
+	assert.Equal(t, []string{"example.com/app"}, spans["resolve"])
+	assert.Equal(t, []string{"go mod download"}, spans["download"])
+	assert.ElementsMatch(t, []string{"linux/amd64", "darwin/arm64"}, spans["list"])
+	assert.ElementsMatch(t, []string{"github.com/fatih/color@v1.18.0", "example.com/localmod => ./localmod"}, spans["hash"])
+	assert.Equal(t, []string{"git ls-files"}, spans["git"])
+}
+
+func TestResolveModuleRecordsScope(t *testing.T) {
+	dir := t.TempDir()
+	goModPath := writeTestFile(t, dir, "go.mod", `
+module example.com/app
+
+go 1.25.4
+
+tool golang.org/x/tools/cmd/stringer
+
+require (
+	github.com/fatih/color v1.18.0
+	github.com/stretchr/testify v1.11.1
+	golang.org/x/tools v0.38.0
+)
+`)
+
+	goMod, err := mod.ParseGoModFile(goModPath)
+	require.NoError(t, err)
+
+	listFmt := listFormat("example.com/app")
+	exec := &fakeExecutor{
+		responses: map[string]string{
+			"go list -deps -f " + listFmt + " ./...": `github.com/fatih/color	github.com/fatih/color`,
+			"go list -deps -test -f " + listFmt + " ./...": `github.com/fatih/color	github.com/fatih/color
+github.com/stretchr/testify	github.com/stretchr/testify/assert`,
+			"go list -deps -f " + listFmt + " tool": `golang.org/x/tools	golang.org/x/tools/cmd/stringer`,
+			"go mod": `{"Path":"github.com/fatih/color","Version":"v1.18.0","Dir":"testdata/module","GoMod":"testdata/module/go.mod"}
+{"Path":"github.com/stretchr/testify","Version":"v1.11.1","Dir":"testdata/module","GoMod":"testdata/module/go.mod"}
+{"Path":"golang.org/x/tools","Version":"v0.38.0","Dir":"testdata/module","GoMod":"testdata/module/go.mod"}`,
+		},
+	}
+
+	r := New(exec)
+	deps, err := r.ResolveModule(context.Background(), goMod, nil)
+	require.NoError(t, err)
+	require.Len(t, deps, 3)
+
+	assert.Equal(t, "github.com/fatih/color", deps[0].Path)
+	assert.Empty(t, deps[0].Scope)
+
+	assert.Equal(t, "github.com/stretchr/testify", deps[1].Path)
+	assert.Equal(t, []string{"test"}, deps[1].Scope)
+
+	assert.Equal(t, "golang.org/x/tools", deps[2].Path)
+	assert.Equal(t, []string{"tool"}, deps[2].Scope)
+}
+
+func TestResolveModuleAlternateModFile(t *testing.T) {
+	dir := t.TempDir()
+	toolsModPath := writeTestFile(t, dir, "tools.mod", `
+module example.com/app
+
+go 1.25.4
+
+tool golang.org/x/tools/cmd/stringer
+
+require golang.org/x/tools v0.38.0
+`)
+
+	goMod, err := mod.ParseGoModFile(toolsModPath)
+	require.NoError(t, err)
+
+	listFmt := listFormat("example.com/app")
+	exec := &fakeExecutor{
+		responses: map[string]string{
+			"go list -modfile=tools.mod -deps -f " + listFmt + " tool": `golang.org/x/tools	golang.org/x/tools/cmd/stringer`,
+			"go mod download -modfile=tools.mod -json":                 `{"Path":"golang.org/x/tools","Version":"v0.38.0","Dir":"testdata/module","GoMod":"testdata/module/go.mod"}`,
+		},
+	}
+
+	r := New(exec)
+	deps, err := r.ResolveModule(context.Background(), goMod, nil)
+	require.NoError(t, err)
+	require.Len(t, deps, 1)
+
+	assert.Equal(t, "golang.org/x/tools", deps[0].Path)
+	assert.Equal(t, []string{"golang.org/x/tools/cmd/stringer"}, deps[0].Packages)
+	assert.Equal(t, []string{"tool"}, deps[0].Scope)
+}
+
+func TestResolveModuleAlternateModFileWithProxy(t *testing.T) {
+	dir := t.TempDir()
+	toolsModPath := writeTestFile(t, dir, "tools.mod", `
+module example.com/app
+
+go 1.25.4
+
+tool golang.org/x/tools/cmd/stringer
+
+require golang.org/x/tools v0.38.0
+`)
+
+	goMod, err := mod.ParseGoModFile(toolsModPath)
+	require.NoError(t, err)
+
+	// tools.sum has no entry for golang.org/x/tools, so it is left for the
+	// go command to download, which must check it against tools.sum too.
+	listFmt := listFormat("example.com/app")
+	exec := &fakeExecutor{
+		responses: map[string]string{
+			"go list -modfile=tools.mod -deps -f " + listFmt + " tool":            `golang.org/x/tools	golang.org/x/tools/cmd/stringer`,
+			"go list -m -modfile=tools.mod -json all":                             `{"Path":"example.com/app","Main":true}{"Path":"golang.org/x/tools","Version":"v0.38.0"}`,
+			"go mod download -modfile=tools.mod -json golang.org/x/tools@v0.38.0": `{"Path":"golang.org/x/tools","Version":"v0.38.0","Dir":"testdata/module","GoMod":"testdata/module/go.mod"}`,
+		},
+	}
+
+	r := New(exec, WithProxy(&fakeFetcher{}))
+	deps, err := r.ResolveModule(context.Background(), goMod, nil)
+	require.NoError(t, err)
+	require.Len(t, deps, 1)
+
+	assert.Equal(t, "golang.org/x/tools", deps[0].Path)
+	assert.Equal(t, []string{"tool"}, deps[0].Scope)
+}
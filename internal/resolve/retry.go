@@ -0,0 +1,95 @@
+package resolve
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/purpleclay/go-overlay/internal/notes"
+)
+
+const (
+	DefaultRetryAttempts = 3
+	DefaultRetryBackoff  = time.Second
+	maxRetryBackoff      = 30 * time.Second
+)
+
+// transientPattern matches stderr output from the Go toolchain that signals
+// a failure worth retrying: network timeouts, dropped connections, and 5xx
+// or 429 responses from a module proxy. The go command and git report a
+// status after a colon, as in "reading <url>: 503 Service Unavailable",
+// which keeps version numbers such as v1.500.0 from matching.
+var transientPattern = regexp.MustCompile(`i/o timeout|TLS handshake timeout|Client\.Timeout exceeded|connection reset by peer|unexpected EOF|: (?:5\d\d|429)\b`)
+
+// RetryExecutor decorates an Executor, re-running `go mod download` and
+// `go list` invocations that fail with a transient error. Attempts is the
+// total number of runs, including the first. Delays grow exponentially from
+// Backoff with random jitter. Every retry is recorded as a note against the
+// context so it can be surfaced in the result message. Other commands and
+// non-transient failures are returned immediately.
+type RetryExecutor struct {
+	Exec     Executor
+	Attempts int
+	Backoff  time.Duration
+}
+
+func (r RetryExecutor) Run(ctx context.Context, args []string, dir string, env []string) (string, error) {
+	out, err := r.Exec.Run(ctx, args, dir, env)
+	if !retryable(args) {
+		return out, err
+	}
+
+	for attempt := 2; attempt <= r.Attempts && isTransient(err); attempt++ {
+		delay := r.delay(attempt - 1)
+		notes.Add(ctx, "retried '%s' (attempt %d/%d) after %s: %s", strings.Join(args[:min(len(args), 3)], " "), attempt, r.Attempts, delay.Round(time.Millisecond), firstLine(err))
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(delay):
+		}
+
+		out, err = r.Exec.Run(ctx, args, dir, env)
+	}
+
+	return out, err
+}
+
+// delay returns the backoff before the given retry (1-based), using
+// "equal jitter": half the exponential delay is fixed, half is random.
+func (r RetryExecutor) delay(retry int) time.Duration {
+	d := min(r.Backoff<<(retry-1), maxRetryBackoff)
+	if d <= 0 {
+		return 0
+	}
+	half := d / 2
+	return half + rand.N(half+1)
+}
+
+func retryable(args []string) bool {
+	if len(args) < 2 || args[0] != "go" {
+		return false
+	}
+	switch args[1] {
+	case "list":
+		return true
+	case "mod":
+		return len(args) > 2 && args[2] == "download"
+	}
+	return false
+}
+
+func isTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	return transientPattern.MatchString(err.Error())
+}
+
+func firstLine(err error) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(err.Error()), "\n")
+	return line
+}
@@ -0,0 +1,124 @@
+package resolve
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/purpleclay/go-overlay/internal/notes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sequenceExecutor returns each error in turn, succeeding once they are
+// exhausted. It counts every invocation.
+type sequenceExecutor struct {
+	errs  []error
+	calls int
+}
+
+func (s *sequenceExecutor) Run(_ context.Context, _ []string, _ string, _ []string) (string, error) {
+	s.calls++
+	if s.calls <= len(s.errs) {
+		return "", s.errs[s.calls-1]
+	}
+	return "ok", nil
+}
+
+func transientErr(stderr string) error {
+	return &ExecError{Err: errors.New("exit status 1"), Stderr: stderr}
+}
+
+func TestRetryExecutorRetriesTransientFailures(t *testing.T) {
+	seq := &sequenceExecutor{errs: []error{
+		transientErr("go: github.com/fatih/color@v1.18.0: reading https://proxy.golang.org/github.com/fatih/color/@v/v1.18.0.zip: 503 Service Unavailable"),
+		transientErr("read tcp 10.0.0.2:51234->142.250.200.17:443: read: connection reset by peer"),
+	}}
+	exec := RetryExecutor{Exec: seq, Attempts: 3}
+
+	ctx, collector := notes.WithCollector(context.Background())
+	out, err := exec.Run(ctx, []string{"go", "mod", "download", "-json"}, ".", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", out)
+	assert.Equal(t, 3, seq.calls)
+
+	recorded := collector.Notes()
+	require.Len(t, recorded, 2)
+	assert.Contains(t, recorded[0], "retried 'go mod download' (attempt 2/3)")
+	assert.Contains(t, recorded[0], "503 Service Unavailable")
+	assert.Contains(t, recorded[1], "retried 'go mod download' (attempt 3/3)")
+	assert.Contains(t, recorded[1], "connection reset by peer")
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want bool
+	}{
+		{msg: "reading https://proxy.golang.org/example.com/lib/@v/v1.0.0.zip: 502 Bad Gateway", want: true},
+		{msg: "reading https://proxy.example.com/example.com/lib/@v/v1.0.0.zip: 520", want: true},
+		{msg: "reading https://proxy.example.com/example.com/lib/@v/list: 429 Too Many Requests", want: true},
+		{msg: "fatal: unable to access 'https://git.example.com/lib/': The requested URL returned error: 503", want: true},
+		{msg: "reading https://proxy.golang.org/example.com/lib/@v/v0.3.0.zip: 410 Gone", want: false},
+		{msg: "example.com/lib@v1.500.0: missing go.sum entry", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.msg, func(t *testing.T) {
+			assert.Equal(t, tt.want, isTransient(transientErr(tt.msg)))
+		})
+	}
+}
+
+func TestRetryExecutorGivesUpAfterAttempts(t *testing.T) {
+	timeout := transientErr("dial tcp 142.250.200.17:443: i/o timeout")
+	seq := &sequenceExecutor{errs: []error{timeout, timeout, timeout}}
+	exec := RetryExecutor{Exec: seq, Attempts: 2}
+
+	_, err := exec.Run(context.Background(), []string{"go", "list", "-deps", "./..."}, ".", nil)
+	require.ErrorIs(t, err, timeout)
+	assert.Equal(t, 2, seq.calls)
+}
+
+func TestRetryExecutorFailsFastOnPermanentFailure(t *testing.T) {
+	seq := &sequenceExecutor{errs: []error{
+		transientErr("go: example.com/lib@v0.3.0: reading https://proxy.golang.org/example.com/lib/@v/v0.3.0.zip: 410 Gone"),
+	}}
+	exec := RetryExecutor{Exec: seq, Attempts: 3}
+
+	_, err := exec.Run(context.Background(), []string{"go", "mod", "download", "-json"}, ".", nil)
+	require.Error(t, err)
+	assert.Equal(t, 1, seq.calls)
+}
+
+func TestRetryExecutorIgnoresOtherCommands(t *testing.T) {
+	seq := &sequenceExecutor{errs: []error{transientErr("i/o timeout")}}
+	exec := RetryExecutor{Exec: seq, Attempts: 3}
+
+	_, err := exec.Run(context.Background(), []string{"git", "ls-files"}, ".", nil)
+	require.Error(t, err)
+	assert.Equal(t, 1, seq.calls)
+}
+
+func TestRetryExecutorStopsWhenContextCancelled(t *testing.T) {
+	seq := &sequenceExecutor{errs: []error{transientErr("i/o timeout"), transientErr("i/o timeout")}}
+	exec := RetryExecutor{Exec: seq, Attempts: 3, Backoff: DefaultRetryBackoff}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := exec.Run(ctx, []string{"go", "list", "./..."}, ".", nil)
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, seq.calls)
+}
+
+func TestRetryExecutorBackoffGrowsExponentially(t *testing.T) {
+	exec := RetryExecutor{Backoff: DefaultRetryBackoff}
+
+	for retry := 1; retry <= 3; retry++ {
+		full := DefaultRetryBackoff << (retry - 1)
+		d := exec.delay(retry)
+		assert.GreaterOrEqual(t, d, full/2)
+		assert.LessOrEqual(t, d, full)
+	}
+	assert.LessOrEqual(t, exec.delay(20), maxRetryBackoff)
+}
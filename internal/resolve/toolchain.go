@@ -0,0 +1,44 @@
+package resolve
+
+import (
+	"context"
+	"fmt"
+	"go/version"
+	"slices"
+	"strings"
+)
+
+// ToolchainExecutor decorates an Executor so every `go` invocation runs the
+// Go binary at Go instead of the first one on PATH. GOTOOLCHAIN=local is set
+// so the binary never switches to, or downloads, a different toolchain when
+// a go.mod asks for a newer one. Other commands are passed through unchanged.
+type ToolchainExecutor struct {
+	Exec Executor
+	Go   string
+}
+
+func (t ToolchainExecutor) Run(ctx context.Context, args []string, dir string, env []string) (string, error) {
+	if len(args) == 0 || args[0] != "go" {
+		return t.Exec.Run(ctx, args, dir, env)
+	}
+
+	args = append([]string{t.Go}, args[1:]...)
+	return t.Exec.Run(ctx, args, dir, append(slices.Clip(env), "GOTOOLCHAIN=local"))
+}
+
+// ToolchainVersion reports the version of the Go toolchain run by exec, such
+// as "go1.25.4".
+func ToolchainVersion(ctx context.Context, exec Executor) (string, error) {
+	out, err := exec.Run(ctx, []string{"go", "env", "GOVERSION"}, ".", nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to read go toolchain version: %w", err)
+	}
+
+	// Development builds report "devel go1.x-<hash> ..." and are not
+	// comparable with a released version.
+	goVersion := strings.TrimSpace(out)
+	if !version.IsValid(goVersion) {
+		return "", fmt.Errorf("unsupported go toolchain version %q, a released toolchain is required", goVersion)
+	}
+	return goVersion, nil
+}
@@ -0,0 +1,67 @@
+package resolve
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// commandRecorder captures the arguments and environment passed to the most
+// recent command.
+type commandRecorder struct {
+	args []string
+	env  []string
+}
+
+func (c *commandRecorder) Run(_ context.Context, args []string, _ string, env []string) (string, error) {
+	c.args = args
+	c.env = env
+	return "", nil
+}
+
+func TestToolchainExecutorRunsSelectedGoBinary(t *testing.T) {
+	rec := &commandRecorder{}
+	exec := ToolchainExecutor{Exec: rec, Go: "/opt/go1.25.4/bin/go"}
+
+	_, err := exec.Run(context.Background(), []string{"go", "mod", "download", "-json"}, ".", []string{"GOWORK=off"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"/opt/go1.25.4/bin/go", "mod", "download", "-json"}, rec.args)
+	assert.Equal(t, []string{"GOWORK=off", "GOTOOLCHAIN=local"}, rec.env)
+}
+
+func TestToolchainExecutorPassesThroughOtherCommands(t *testing.T) {
+	rec := &commandRecorder{}
+	exec := ToolchainExecutor{Exec: rec, Go: "/opt/go1.25.4/bin/go"}
+
+	_, err := exec.Run(context.Background(), []string{"git", "ls-files"}, ".", nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"git", "ls-files"}, rec.args)
+	assert.Empty(t, rec.env)
+}
+
+func TestToolchainVersion(t *testing.T) {
+	exec := &fakeExecutor{
+		responses: map[string]string{
+			"go env GOVERSION": "go1.25.4\n",
+		},
+	}
+
+	goVersion, err := ToolchainVersion(context.Background(), exec)
+	require.NoError(t, err)
+	assert.Equal(t, "go1.25.4", goVersion)
+}
+
+func TestToolchainVersionRejectsDevelopmentBuilds(t *testing.T) {
+	exec := &fakeExecutor{
+		responses: map[string]string{
+			"go env GOVERSION": "devel go1.26-a1b2c3d Mon Jan 5 10:00:00 2026 +0000\n",
+		},
+	}
+
+	_, err := ToolchainVersion(context.Background(), exec)
+	require.ErrorContains(t, err, "a released toolchain is required")
+}
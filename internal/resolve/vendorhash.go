@@ -0,0 +1,44 @@
+package resolve
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// VendorHash computes the vendorHash nixpkgs' buildGoModule expects for the
+// module or workspace in dir: the NAR hash of the vendor directory written
+// by `go mod vendor`, or `go work vendor` when dir holds a go.work file. The
+// vendor directory is written to a temporary location, leaving dir
+// untouched. An empty hash is returned when there is nothing to vendor, for
+// which buildGoModule expects `vendorHash = null`.
+//
+// The layout of vendor/modules.txt depends on the Go version, so the hash
+// only matches when exec runs the same Go toolchain as the Nix build.
+func VendorHash(ctx context.Context, exec Executor, dir string) (string, error) {
+	tmp, err := os.MkdirTemp("", "govendor-vendor-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+
+	cmd := "mod"
+	if _, err := os.Stat(filepath.Join(dir, "go.work")); err == nil {
+		cmd = "work"
+	}
+
+	vendorDir := filepath.Join(tmp, "vendor")
+	if _, err := exec.Run(ctx, []string{"go", cmd, "vendor", "-o", vendorDir}, dir, nil); err != nil {
+		return "", err
+	}
+
+	entries, err := os.ReadDir(vendorDir)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && len(entries) == 0) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return NARHash(vendorDir)
+}
@@ -0,0 +1,37 @@
+package resolve
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVendorHash(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "go.mod", "module example.com/app\n\ngo 1.22\n\nrequire example.com/dep v0.0.0\n\nreplace example.com/dep => ./dep\n")
+	writeTestFile(t, dir, "main.go", "package main\n\nimport _ \"example.com/dep\"\n\nfunc main() {}\n")
+	writeTestFile(t, dir, "dep/go.mod", "module example.com/dep\n\ngo 1.22\n")
+	writeTestFile(t, dir, "dep/dep.go", "package dep\n")
+
+	hash, err := VendorHash(context.Background(), OSExecutor{}, dir)
+	require.NoError(t, err)
+	assert.Regexp(t, `^sha256-[A-Za-z0-9+/]{43}=$`, hash)
+	assert.NoDirExists(t, filepath.Join(dir, "vendor"))
+
+	again, err := VendorHash(context.Background(), OSExecutor{}, dir)
+	require.NoError(t, err)
+	assert.Equal(t, hash, again)
+}
+
+func TestVendorHashWithoutDependencies(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "go.mod", "module example.com/app\n\ngo 1.22\n")
+	writeTestFile(t, dir, "main.go", "package main\n\nfunc main() {}\n")
+
+	hash, err := VendorHash(context.Background(), OSExecutor{}, dir)
+	require.NoError(t, err)
+	assert.Empty(t, hash)
+}
@@ -0,0 +1,143 @@
+package resolve
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/nix-community/go-nix/pkg/nar"
+	"golang.org/x/mod/module"
+	modzip "golang.org/x/mod/zip"
+)
+
+// zipNode is a directory or file within a module zip, arranged as the tree
+// the go command extracts into its module cache.
+type zipNode struct {
+	file     *zip.File
+	children map[string]*zipNode
+}
+
+// NARHashZip computes the NAR hash of a module zip in SRI format, without
+// extracting it. The hash is identical to NARHash of the directory the go
+// command extracts the zip into: every file is read-only and never
+// executable, and directories only exist where they contain files.
+func NARHashZip(zipFile string, m module.Version) (string, error) {
+	h := sha256.New()
+	if err := WriteZipNAR(h, zipFile, m); err != nil {
+		return "", err
+	}
+	return "sha256-" + base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// WriteZipNAR writes the NAR serialisation of a module zip to w, exactly as
+// NARHashZip hashes it.
+func WriteZipNAR(w io.Writer, zipFile string, m module.Version) error {
+	if _, err := modzip.CheckZip(m, zipFile); err != nil {
+		return fmt.Errorf("invalid module zip %s: %w", zipFile, err)
+	}
+
+	zr, err := zip.OpenReader(zipFile)
+	if err != nil {
+		return fmt.Errorf("failed to open module zip %s: %w", zipFile, err)
+	}
+	defer zr.Close()
+
+	return writeZipReaderNAR(w, &zr.Reader, m, zipFile)
+}
+
+// narHashZipReader computes the NAR hash of a module zip already open for
+// reading, named in errors by name.
+func narHashZipReader(zr *zip.Reader, m module.Version, name string) (string, error) {
+	h := sha256.New()
+	if err := writeZipReaderNAR(h, zr, m, name); err != nil {
+		return "", err
+	}
+	return "sha256-" + base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+func writeZipReaderNAR(w io.Writer, zr *zip.Reader, m module.Version, zipFile string) error {
+	root := &zipNode{children: make(map[string]*zipNode)}
+	prefix := m.Path + "@" + m.Version + "/"
+	for _, f := range zr.File {
+		name := strings.TrimPrefix(f.Name, prefix)
+		// Directory entries are skipped when extracting, so empty
+		// directories never reach the module cache.
+		if name == "" || strings.HasSuffix(name, "/") {
+			continue
+		}
+
+		node := root
+		elems := strings.Split(name, "/")
+		for _, dir := range elems[:len(elems)-1] {
+			child, ok := node.children[dir]
+			if !ok {
+				child = &zipNode{children: make(map[string]*zipNode)}
+				node.children[dir] = child
+			}
+			node = child
+		}
+		node.children[elems[len(elems)-1]] = &zipNode{file: f}
+	}
+
+	nw, err := nar.NewWriter(w)
+	if err != nil {
+		return err
+	}
+	defer nw.Close()
+
+	if err := writeZipNode(nw, "/", root); err != nil {
+		return fmt.Errorf("failed to serialise module zip %s: %w", zipFile, err)
+	}
+	if err := nw.Close(); err != nil {
+		return fmt.Errorf("failed to serialise module zip %s: %w", zipFile, err)
+	}
+	return nil
+}
+
+// writeZipNode writes a node and its children in NAR order, which requires
+// directory entries sorted by name.
+func writeZipNode(nw *nar.Writer, path string, node *zipNode) error {
+	if node.file != nil {
+		if err := nw.WriteHeader(&nar.Header{
+			Path: path,
+			Type: nar.TypeRegular,
+			Size: int64(node.file.UncompressedSize64),
+		}); err != nil {
+			return err
+		}
+
+		rc, err := node.file.Open()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+
+		_, err = io.Copy(nw, rc)
+		return err
+	}
+
+	if err := nw.WriteHeader(&nar.Header{Path: path, Type: nar.TypeDirectory}); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(node.children))
+	for name := range node.children {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		childPath := path + "/" + name
+		if path == "/" {
+			childPath = "/" + name
+		}
+		if err := writeZipNode(nw, childPath, node.children[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package trace_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/purpleclay/go-overlay/internal/trace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorderAssignsOverlappingSpansToSeparateLanes(t *testing.T) {
+	rec := trace.New()
+
+	endOuter := rec.Start("download", "go mod download", nil)
+	endInner := rec.Start("list", "linux/amd64", map[string]any{"dir": "."})
+	endInner()
+	endOuter()
+
+	next := rec.Start("hash", "example.com/mod@v1.0.0", nil)
+	next()
+
+	events := rec.Events()
+	require.Len(t, events, 3)
+
+	assert.Equal(t, "linux/amd64", events[0].Name)
+	assert.Equal(t, 2, events[0].TID)
+	assert.Equal(t, "go mod download", events[1].Name)
+	assert.Equal(t, 1, events[1].TID)
+	assert.Equal(t, "example.com/mod@v1.0.0", events[2].Name)
+	assert.Equal(t, 1, events[2].TID)
+
+	for _, e := range events {
+		assert.Equal(t, "X", e.Phase)
+	}
+}
+
+func TestRecorderWriteTo(t *testing.T) {
+	rec := trace.New()
+	rec.Start("git", "git ls-files", map[string]any{"dir": "/src/lib"})()
+
+	var buf bytes.Buffer
+	_, err := rec.WriteTo(&buf)
+	require.NoError(t, err)
+
+	var doc struct {
+		TraceEvents []map[string]any `json:"traceEvents"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	require.Len(t, doc.TraceEvents, 1)
+	assert.Equal(t, "git ls-files", doc.TraceEvents[0]["name"])
+	assert.Equal(t, "git", doc.TraceEvents[0]["cat"])
+	assert.Equal(t, map[string]any{"dir": "/src/lib"}, doc.TraceEvents[0]["args"])
+}
+
+func TestNilRecorderIsNoOp(t *testing.T) {
+	var rec *trace.Recorder
+	rec.Start("hash", "noop", nil)()
+	assert.Empty(t, rec.Events())
+}
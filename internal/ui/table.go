@@ -1,9 +1,12 @@
 package ui
 
 import (
+	"encoding/json"
+
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/lipgloss/table"
 	"github.com/muesli/reflow/wordwrap"
+	"github.com/purpleclay/go-overlay/internal/nixcache"
 	"github.com/purpleclay/go-overlay/internal/vendor"
 )
 
@@ -31,7 +34,7 @@ func StatusSymbol(s vendor.Status) string {
 	switch s {
 	case vendor.StatusOK, vendor.StatusGenerated:
 		return greenStyle.Render("✓")
-	case vendor.StatusDrift, vendor.StatusMissing, vendor.StatusError:
+	case vendor.StatusDrift, vendor.StatusMissing, vendor.StatusError, vendor.StatusTimeout:
 		return redStyle.Render("✗")
 	default:
 		return " "
@@ -43,7 +46,7 @@ func StatusLabel(s vendor.Status) string {
 	switch s {
 	case vendor.StatusOK, vendor.StatusGenerated:
 		return greenStyle.Render(string(s))
-	case vendor.StatusDrift, vendor.StatusMissing, vendor.StatusError:
+	case vendor.StatusDrift, vendor.StatusMissing, vendor.StatusError, vendor.StatusTimeout:
 		return redStyle.Render(string(s))
 	default:
 		return string(s)
@@ -51,13 +54,22 @@ func StatusLabel(s vendor.Status) string {
 }
 
 // RenderResultsTable formats a slice of results as a bordered terminal table
-// with coloured status indicators.
+// with coloured status indicators. Remediation hints for classified errors
+// are shown beneath the message, and results for a profile name it after
+// the file.
 func RenderResultsTable(results []vendor.Result) string {
 	var rows [][]string
 	for _, r := range results {
+		file := r.Path
+		if r.Profile != "" {
+			file += " (" + r.Profile + ")"
+		}
 		status := StatusSymbol(r.Status) + " " + StatusLabel(r.Status)
 		message := wordwrap.String(r.Message, messageWrapWidth)
-		rows = append(rows, []string{r.Path, status, message})
+		if r.Hint != "" {
+			message += "\n" + wordwrap.String("hint: "+r.Hint, messageWrapWidth)
+		}
+		rows = append(rows, []string{file, status, message})
 	}
 
 	t := table.New().
@@ -77,3 +89,62 @@ func RenderResultsTable(results []vendor.Result) string {
 
 	return t.Render()
 }
+
+// RenderResultsJSON formats a slice of results as an indented JSON array,
+// for consumption by CI tooling.
+func RenderResultsJSON(results []vendor.Result) (string, error) {
+	if results == nil {
+		results = []vendor.Result{}
+	}
+
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// RenderCacheStatusTable formats the binary cache status of modules as a
+// bordered terminal table, showing the cache each substitutable module is
+// served from.
+func RenderCacheStatusTable(statuses []nixcache.ModuleStatus) string {
+	var rows [][]string
+	for _, s := range statuses {
+		status := redStyle.Render("✗") + " " + redStyle.Render("fetch")
+		if s.Substitutable() {
+			status = greenStyle.Render("✓") + " " + greenStyle.Render("cached")
+		}
+		rows = append(rows, []string{s.Path, s.Version, status, s.Cache})
+	}
+
+	t := table.New().
+		Border(lipgloss.RoundedBorder()).
+		BorderStyle(borderStyle).
+		Headers("Module", "Version", "Status", "Cache").
+		StyleFunc(func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return headerStyle
+			}
+			if col == 3 {
+				return messageStyle
+			}
+			return cellStyle
+		}).
+		Rows(rows...)
+
+	return t.Render()
+}
+
+// RenderCacheStatusJSON formats the binary cache status of modules as an
+// indented JSON array, for consumption by CI tooling.
+func RenderCacheStatusJSON(statuses []nixcache.ModuleStatus) (string, error) {
+	if statuses == nil {
+		statuses = []nixcache.ModuleStatus{}
+	}
+
+	data, err := json.MarshalIndent(statuses, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
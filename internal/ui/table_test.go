@@ -4,8 +4,10 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/purpleclay/go-overlay/internal/nixcache"
 	"github.com/purpleclay/go-overlay/internal/ui"
 	"github.com/purpleclay/go-overlay/internal/vendor"
+	"github.com/stretchr/testify/require"
 	"gotest.tools/v3/golden"
 )
 
@@ -44,3 +46,54 @@ func TestRenderResultsTableForGoWork(t *testing.T) {
 	got := ui.RenderResultsTable(results)
 	golden.Assert(t, got, "table_gowork.golden")
 }
+
+func TestRenderResultsTableWithHint(t *testing.T) {
+	results := []vendor.Result{
+		{
+			Path:    "path/to/private/go.mod",
+			Status:  vendor.StatusError,
+			Message: "go: git.example.com/team/lib@v1.2.0: reading https://proxy.golang.org/git.example.com/team/lib/@v/v1.2.0.mod: 401 Unauthorized",
+			Class:   "auth",
+			Hint:    "set GOPRIVATE and netrcFile for private modules",
+		},
+	}
+
+	got := ui.RenderResultsTable(results)
+	golden.Assert(t, got, "table_hint.golden")
+}
+
+func TestRenderResultsJSON(t *testing.T) {
+	results := []vendor.Result{
+		{Path: "path/to/ok/go.mod", Status: vendor.StatusOK, Message: "govendor.toml is up to date"},
+		{
+			Path:    "path/to/private/go.mod",
+			Status:  vendor.StatusError,
+			Message: "go: git.example.com/team/lib@v1.2.0: 401 Unauthorized",
+			Class:   "auth",
+			Hint:    "set GOPRIVATE and netrcFile for private modules",
+		},
+	}
+
+	got, err := ui.RenderResultsJSON(results)
+	require.NoError(t, err)
+	golden.Assert(t, got, "results_json.golden")
+}
+
+func TestRenderCacheStatusTable(t *testing.T) {
+	statuses := []nixcache.ModuleStatus{
+		{
+			Path:      "github.com/spf13/cobra",
+			Version:   "v1.10.1",
+			StorePath: "/nix/store/0hvrl9wn0d65l5c4y3b6bkpiv7xrfhkr-github.com-spf13-cobra_v1.10.1",
+			Cache:     "https://cache.example.com",
+		},
+		{
+			Path:      "golang.org/x/mod",
+			Version:   "v0.29.0",
+			StorePath: "/nix/store/38gaqwhdigq7jsgyxdz9hx44n0grqa1a-golang.org-x-mod_v0.29.0",
+		},
+	}
+
+	got := ui.RenderCacheStatusTable(statuses)
+	golden.Assert(t, got, "cache_status.golden")
+}
@@ -436,7 +436,7 @@ in {
     assertHostToolDrvPathStable "hostTool-workspace-drvpath-stable-across-unrelated-changes" mkDrv baseSrc touchedSrc;
 
   mkModuleCopyCommands-works-with-tildes-symlink = let
-    inherit (import ../builder/vendor-env.nix {inherit (pkgs) lib runCommand fetchGoModule;}) mkModuleCopyCommands;
+    inherit (import ../builder/vendor-env.nix {inherit (pkgs) lib runCommand fetchGoModule fetchGoModuleZip;}) mkModuleCopyCommands;
 
     sources = {
       "git.sr.ht/~sbinet/gg" = ./fixtures/mkModuleCopyCommands-module;
@@ -460,7 +460,7 @@ in {
     );
 
   mkModuleCopyCommands-works-with-tildes-copy = let
-    inherit (import ../builder/vendor-env.nix {inherit (pkgs) lib runCommand fetchGoModule;}) mkModuleCopyCommands;
+    inherit (import ../builder/vendor-env.nix {inherit (pkgs) lib runCommand fetchGoModule fetchGoModuleZip;}) mkModuleCopyCommands;
 
     sources = {
       "git.sr.ht/~sbinet/gg" = ./fixtures/mkModuleCopyCommands-module;